@@ -0,0 +1,143 @@
+package instapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// fakeAPI serves the schemas and records of a single account from memory,
+// logging the requests made.
+type fakeAPI struct {
+	mu       sync.Mutex
+	schemas  map[string]*schema.Schema
+	records  map[string][]map[string]interface{}
+	requests []string
+}
+
+func newFakeAPI(t *testing.T, schemas ...*schema.Schema) (*fakeAPI, *Client) {
+	api := &fakeAPI{schemas: map[string]*schema.Schema{}, records: map[string][]map[string]interface{}{}}
+
+	for _, v := range schemas {
+		api.schemas[v.Name] = v
+	}
+
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	return api, New(Endpoint(srv.URL + "/"))
+}
+
+func (api *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.requests = append(api.requests, r.Method+" "+r.URL.RequestURI())
+
+	// accounts/{account}/schemas[/{name}[/records[/{id}]]]
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[2:]
+
+	switch {
+	case len(path) == 1 && r.Method == http.MethodGet:
+		s := make([]*schema.Schema, 0, len(api.schemas))
+
+		for _, v := range api.schemas {
+			s = append(s, v)
+		}
+
+		sort.Slice(s, func(i, j int) bool { return s[i].Name < s[j].Name })
+		api.write(w, http.StatusOK, s)
+
+	case len(path) == 1 && r.Method == http.MethodPost:
+		var s *schema.Schema
+		_ = json.NewDecoder(r.Body).Decode(&s)
+		api.schemas[s.Name] = s
+		w.WriteHeader(http.StatusCreated)
+
+	case len(path) == 2 && r.Method == http.MethodGet:
+		api.write(w, http.StatusOK, api.schemas[path[1]])
+
+	case len(path) == 2 && r.Method == http.MethodPut:
+		var s *schema.Schema
+		_ = json.NewDecoder(r.Body).Decode(&s)
+		delete(api.schemas, path[1])
+		api.schemas[s.Name] = s
+
+		if s.Name != path[1] {
+			api.records[s.Name] = api.records[path[1]]
+			delete(api.records, path[1])
+		}
+
+		api.dropFields(s)
+
+	case len(path) == 2 && r.Method == http.MethodDelete:
+		delete(api.schemas, path[1])
+		w.WriteHeader(http.StatusNoContent)
+
+	case len(path) == 3 && r.Method == http.MethodGet:
+		records := api.records[path[1]]
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		if skip > len(records) {
+			skip = len(records)
+		}
+
+		if limit > 0 && skip+limit < len(records) {
+			records = records[:skip+limit]
+		}
+
+		api.write(w, http.StatusOK, records[skip:])
+
+	case len(path) == 4 && r.Method == http.MethodPatch:
+		var m map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&m)
+
+		for _, v := range api.records[path[1]] {
+			if v["instapi:id"] == path[3] {
+				for k, x := range m {
+					v[k] = x
+				}
+			}
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// dropFields removes the values of the fields missing from the schema, as
+// the API does when fields are removed.
+func (api *fakeAPI) dropFields(s *schema.Schema) {
+	for _, v := range api.records[s.Name] {
+		for k := range v {
+			if k != "instapi:id" && s.Field(k) == nil {
+				delete(v, k)
+			}
+		}
+	}
+}
+
+func (api *fakeAPI) write(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// log returns and resets the requests made.
+func (api *fakeAPI) log() []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	requests := api.requests
+	api.requests = nil
+
+	return requests
+}
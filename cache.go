@@ -0,0 +1,298 @@
+package instapi
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores GET responses. Implementations must be safe for concurrent
+// use.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+	// Invalidate removes the responses whose key has the given prefix.
+	Invalidate(prefix string)
+}
+
+// CachedResponse represents a cached response. Responses are revalidated
+// with the API once expired.
+type CachedResponse struct {
+	Header  http.Header
+	Body    []byte
+	Expires time.Time
+}
+
+// ResponseCache option caches GET responses, keyed by URL, authorization and
+// accepted content type. Cached responses are served until they expire per
+// their Cache-Control or Expires headers, then revalidated using their ETag
+// or Last-Modified headers. Successful requests of other methods invalidate
+// the cached responses of the resource, its subresources and its parent.
+func ResponseCache(cache Cache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// fetch sends the request, using the cache if any.
+func (c *Client) fetch(req *http.Request) (*http.Response, error) {
+	if c.cache == nil {
+		return c.doer.Do(req)
+	}
+
+	if req.Method != http.MethodGet {
+		resp, err := c.doer.Do(req)
+
+		if err == nil && req.Method != http.MethodHead && resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+			c.invalidate(req.URL)
+		}
+
+		return resp, err
+	}
+
+	key := c.cacheKey(req)
+	cached, exists := c.cache.Get(key)
+
+	if exists && time.Now().Before(cached.Expires) {
+		return cached.response(req), nil
+	}
+
+	if exists {
+		if v := cached.Header.Get("ETag"); v != "" {
+			req.Header.Set("If-None-Match", v)
+		}
+
+		if v := cached.Header.Get("Last-Modified"); v != "" {
+			req.Header.Set("If-Modified-Since", v)
+		}
+	}
+
+	resp, err := c.doer.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exists && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close() // nolint: errcheck
+
+		header := cached.Header.Clone()
+
+		for _, k := range []string{"Cache-Control", "Date", "Expires", "ETag", "Last-Modified", "Age"} {
+			if v, exists := resp.Header[k]; exists {
+				header[k] = v
+			}
+		}
+
+		cached = &CachedResponse{Header: header, Body: cached.Body, Expires: expires(header)}
+		c.cache.Set(key, cached)
+
+		return cached.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	if !cacheable(resp.Header) {
+		if exists {
+			c.cache.Invalidate(key)
+		}
+
+		return resp, nil
+	}
+
+	defer resp.Body.Close() // nolint: errcheck
+
+	b, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.Set(key, &CachedResponse{Header: resp.Header.Clone(), Body: b, Expires: expires(resp.Header)})
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	return resp, nil
+}
+
+// cacheKey returns the cache key of the request, the URL followed by the
+// authorization identity and accepted content type.
+func (c *Client) cacheKey(req *http.Request) string {
+	identity := ""
+
+	if v := req.Header.Get("Authorization"); v != "" {
+		sum := sha256.Sum256([]byte(v))
+		identity = hex.EncodeToString(sum[:8])
+	}
+
+	return resourceKey(req.URL, req.URL.Path) + "?" + req.URL.RawQuery + "#" + identity + "#" + req.Header.Get("Accept")
+}
+
+// invalidate removes the cached responses of the resource, its subresources
+// and its parent collection.
+func (c *Client) invalidate(u *url.URL) {
+	p := strings.TrimSuffix(u.Path, "/")
+
+	c.cache.Invalidate(resourceKey(u, p) + "?")
+	c.cache.Invalidate(resourceKey(u, p) + "/")
+	c.cache.Invalidate(resourceKey(u, path.Dir(p)) + "?")
+}
+
+func resourceKey(u *url.URL, p string) string {
+	return u.Scheme + "://" + u.Host + p
+}
+
+func (r *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// cacheControl parses the Cache-Control directives.
+func cacheControl(header http.Header) map[string]string {
+	directives := map[string]string{}
+
+	for _, v := range strings.Split(strings.Join(header.Values("Cache-Control"), ","), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(v), "=")
+
+		if k != "" {
+			directives[strings.ToLower(k)] = strings.Trim(v, `"`)
+		}
+	}
+
+	return directives
+}
+
+// cacheable reports whether the response may be stored, which requires it to
+// be fresh for a while or revalidatable.
+func cacheable(header http.Header) bool {
+	if _, exists := cacheControl(header)["no-store"]; exists {
+		return false
+	}
+
+	return header.Get("ETag") != "" || header.Get("Last-Modified") != "" || time.Now().Before(expires(header))
+}
+
+// expires returns the time the response becomes stale.
+func expires(header http.Header) time.Time {
+	directives := cacheControl(header)
+
+	if _, exists := directives["no-cache"]; exists {
+		return time.Time{}
+	}
+
+	if v, exists := directives["max-age"]; exists {
+		maxAge, err := strconv.Atoi(v)
+
+		if err != nil {
+			return time.Time{}
+		}
+
+		age, _ := strconv.Atoi(header.Get("Age"))
+
+		return time.Now().Add(time.Duration(maxAge-age) * time.Second)
+	}
+
+	t, err := http.ParseTime(header.Get("Expires"))
+
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// LRUCache is an in-memory Cache holding a maximum number of responses,
+// evicting the least recently used.
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key  string
+	resp *CachedResponse
+}
+
+// NewLRUCache creates a cache holding up to size responses.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{size: size, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+// Get returns the cached response for the key.
+func (c *LRUCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, exists := c.items[key]
+
+	if !exists {
+		return nil, false
+	}
+
+	c.ll.MoveToFront(e)
+
+	return e.Value.(*lruEntry).resp, true
+}
+
+// Set caches the response for the key.
+func (c *LRUCache) Set(key string, resp *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, exists := c.items[key]; exists {
+		e.Value.(*lruEntry).resp = resp
+		c.ll.MoveToFront(e)
+
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, resp: resp})
+
+	for c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*lruEntry).key)
+	}
+}
+
+// Invalidate removes the responses whose key has the given prefix.
+func (c *LRUCache) Invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.items {
+		if strings.HasPrefix(k, prefix) {
+			c.ll.Remove(e)
+			delete(c.items, k)
+		}
+	}
+}
+
+// Len returns the number of cached responses.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
@@ -0,0 +1,133 @@
+package instapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/instapi/client-go/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	var (
+		hits        int32
+		revalidated int32
+		cacheCtl    = "no-cache"
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			return
+		}
+
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", cacheCtl)
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidated, 1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"name": "people"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		ctx   = context.Background()
+		cache = NewLRUCache(10)
+		c     = New(Endpoint(srv.URL+"/"), Token("a"), ResponseCache(cache))
+	)
+
+	get := func(c *Client) {
+		s, err := c.GetSchema(ctx, "acme", "people")
+
+		require.NoError(t, err)
+		require.Equal(t, "people", s.Name)
+	}
+
+	// Revalidated with the ETag
+	get(c)
+	get(c)
+	require.Equal(t, int32(2), hits)
+	require.Equal(t, int32(1), revalidated)
+
+	// Fresh responses are served from the cache
+	cacheCtl = "max-age=60"
+	get(c)
+	get(c)
+	require.Equal(t, int32(3), hits)
+	require.Equal(t, int32(2), revalidated)
+
+	// Other identities are cached separately
+	get(New(Endpoint(srv.URL+"/"), Token("b"), ResponseCache(cache)))
+	require.Equal(t, int32(4), hits)
+	require.Equal(t, 2, cache.Len())
+
+	// Mutations invalidate the resource
+	require.NoError(t, c.UpdateSchema(ctx, "acme", "people", &schema.Schema{Name: "people"}))
+	require.Zero(t, cache.Len())
+	get(c)
+	require.Equal(t, int32(5), hits)
+	require.Equal(t, int32(2), revalidated)
+
+	// No store responses are not cached
+	cacheCtl = "no-store"
+	require.NoError(t, c.DeleteSchema(ctx, "acme", "people"))
+	get(c)
+	get(c)
+	require.Equal(t, int32(7), hits)
+	require.Zero(t, cache.Len())
+}
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", &CachedResponse{})
+	c.Set("b", &CachedResponse{})
+
+	_, exists := c.Get("a")
+	require.True(t, exists)
+
+	c.Set("c", &CachedResponse{})
+
+	_, exists = c.Get("b")
+	require.False(t, exists)
+	require.Equal(t, 2, c.Len())
+
+	c.Set("a/1", &CachedResponse{})
+	c.Invalidate("a")
+	require.Equal(t, 1, c.Len())
+}
+
+func TestInvalidate(t *testing.T) {
+	var (
+		cache = NewLRUCache(10)
+		c     = New(ResponseCache(cache))
+		keys  = []string{
+			"https://api/v1/accounts/acme/schemas?#id#json",
+			"https://api/v1/accounts/acme/schemas/people?#id#json",
+			"https://api/v1/accounts/acme/schemas/people/records?limit=1#id#json",
+			"https://api/v1/accounts/acme/schemas/places?#id#json",
+			"https://api/v1/accounts/acme?#id#json",
+		}
+	)
+
+	for _, k := range keys {
+		cache.Set(k, &CachedResponse{})
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, "https://api/v1/accounts/acme/schemas/people", nil)
+	require.NoError(t, err)
+
+	c.invalidate(req.URL)
+
+	for i, k := range keys {
+		_, exists := cache.Get(k)
+		require.Equal(t, i >= 3, exists, k)
+	}
+}
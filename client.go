@@ -10,11 +10,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/instapi/client-go/internal/csvutil"
+	"github.com/instapi/client-go/schema"
 	"github.com/instapi/client-go/types"
 
 	"github.com/tomnomnom/linkheader"
@@ -32,6 +33,9 @@ var (
 	ErrForbidden       = errors.New("forbidden")
 	ErrUnauthorized    = errors.New("unauthorized")
 	ErrStatus          = errors.New("unexpected HTTP status")
+
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrNotAcceptable        = errors.New("response media type not acceptable")
 )
 
 // Client represents a client implementation.
@@ -40,6 +44,13 @@ type Client struct {
 	debugFunc func(*http.Request, *http.Response, Debug)
 	endpoint  string
 	token     string
+	compress  *compression
+	cache     Cache
+	flight    *flightGroup
+	timeouts  timeouts
+	dialect   schema.Dialect
+	unbound   bool
+	mu        sync.RWMutex
 }
 
 // Doer defines the HTTP Do() interface.
@@ -71,6 +82,14 @@ func Token(token string) ClientOption {
 	}
 }
 
+// SQLDialect option sets the SQL dialect of the API, used to escape query
+// arguments client side when the API does not accept bound parameters.
+func SQLDialect(dialect schema.Dialect) ClientOption {
+	return func(c *Client) {
+		c.dialect = dialect
+	}
+}
+
 // DebugFunc option.
 func DebugFunc(f func(*http.Request, *http.Response, Debug)) ClientOption {
 	return func(c *Client) {
@@ -91,7 +110,11 @@ func New(options ...ClientOption) *Client {
 	}
 
 	if c.doer == nil {
-		c.doer = http.DefaultClient
+		c.doer = c.timeouts.defaultDoer()
+	}
+
+	if c.dialect == "" {
+		c.dialect = schema.SQLite
 	}
 
 	return c
@@ -121,131 +144,294 @@ type Debug struct {
 }
 
 func (c *Client) doRequest(ctx context.Context, method, contentType, endpoint string, statusCode int, src, dst interface{}, options ...RequestOption) (*http.Response, []byte, error) {
-	var (
-		r       io.Reader
-		payload []byte
-	)
+	nilDst := dst == nil
+	resp, err := c.send(ctx, method, contentType, endpoint, src, nilDst, options)
 
-	if src != nil {
-		if v, ok := src.(io.Reader); ok {
-			// Optimize sending large CSV payloads with a record limit
-			if contentType == types.CSV {
-				limit := 0
-				headers := true // Headers are assumed by default
-
-				for _, option := range options {
-					switch option.param {
-					case "limit":
-						limit = option.value.(int)
-
-					case "headers":
-						headers = option.value.(bool)
-					}
-				}
+	if err != nil {
+		return nil, nil, err
+	}
 
-				if limit > 0 {
-					if headers {
-						limit++
-					}
+	defer resp.Body.Close() // nolint: errcheck
 
-					r = csvutil.NewLineLimitReader(v, limit)
-				}
-			}
+	// Early exit for successful HTTP status code and nil destination
+	if nilDst &&
+		(resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent) &&
+		(statusCode == http.StatusOK || statusCode == http.StatusNoContent) {
+		return resp, nil, nil
+	}
 
-			if r == nil {
-				r = v
-			}
-		} else {
-			var err error
-			payload, err = json.Marshal(src)
+	b, err := io.ReadAll(resp.Body)
 
-			if err != nil {
-				return nil, nil, err
-			}
+	if err != nil {
+		return nil, nil, err
+	}
 
-			r = bytes.NewReader(payload)
-		}
+	if statusCode > 0 &&
+		resp.StatusCode != statusCode ||
+		(resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices) {
+		return nil, nil, statusError(method, contentType, endpoint, statusCode, resp.StatusCode, b)
 	}
 
-	req, err := c.newRequest(ctx, method, endpoint, r)
+	if dst != nil {
+		return resp, b, json.Unmarshal(b, &dst)
+	}
+
+	return resp, b, nil
+}
+
+// doStream makes a request returning the response with its body unread on
+// success. The caller must close the response body.
+func (c *Client) doStream(ctx context.Context, method, contentType, endpoint string, statusCode int, src interface{}, options ...RequestOption) (*http.Response, error) {
+	resp, err := c.send(ctx, method, contentType, endpoint, src, false, options)
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	req.Header.Add("Accept", contentType)
-	req.Header.Add("Content-Type", contentType)
+	if resp.StatusCode != statusCode {
+		defer resp.Body.Close() // nolint: errcheck
 
-	nilDst := dst == nil
+		b, err := io.ReadAll(resp.Body)
 
-	if nilDst {
-		switch method {
-		case http.MethodPatch, http.MethodPost, http.MethodPut:
-			req.Header.Add("No-Response-Body", "1")
+		if err != nil {
+			return nil, err
 		}
+
+		return nil, statusError(method, contentType, endpoint, statusCode, resp.StatusCode, b)
 	}
 
-	q := req.URL.Query()
+	return resp, nil
+}
 
-	for _, option := range options {
-		option.fn(&q)
+// send makes a request limited by the timeouts, retrying uncompressed when
+// the API does not accept the content encoding, and returns the decompressed
+// response.
+func (c *Client) send(ctx context.Context, method, contentType, endpoint string, src interface{}, nilDst bool, options []RequestOption) (*http.Response, error) {
+	ctx, wd := c.watch(ctx, c.operation(method, endpoint, src), options)
+	resp, err := c.exchange(ctx, wd, method, contentType, endpoint, src, nilDst, options)
+
+	if err != nil && wd != nil {
+		err = wd.failure(err)
+		wd.stop()
 	}
 
-	req.URL.RawQuery = q.Encode()
+	return resp, err
+}
+
+// exchange makes the request, tracking its progress with the watchdog if any.
+func (c *Client) exchange(ctx context.Context, wd *watchdog, method, contentType, endpoint string, src interface{}, nilDst bool, options []RequestOption) (*http.Response, error) {
+	open, payload, err := requestBody(contentType, src, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, body, err := c.buildRequest(ctx, method, contentType, endpoint, open, nilDst, true, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wd.watchRequest(req)
 	start := time.Now()
-	resp, err := c.doer.Do(req)
+	resp, err := c.do(req)
+
+	if err == nil && resp.StatusCode == http.StatusUnsupportedMediaType && body != nil {
+		resp.Body.Close() // nolint: errcheck
+		req, _, err = c.buildRequest(ctx, method, contentType, endpoint, body.uncompressed(open), nilDst, false, options)
+
+		if err != nil {
+			return nil, err
+		}
+
+		wd.watchRequest(req)
+		start = time.Now()
+		resp, err = c.do(req)
+	}
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	d := time.Since(start)
+	resp.Body = wd.watchResponse(resp.Body)
+	err = c.decompressBody(resp)
 
-	defer resp.Body.Close() // nolint: errcheck
+	if err != nil {
+		resp.Body.Close() // nolint: errcheck
+		return nil, err
+	}
 
 	if c.debugFunc != nil {
 		c.debugFunc(req, resp, Debug{Payload: payload, Duration: d})
 	}
 
-	// Early exit for successful HTTP status code and nil destination
-	if nilDst &&
-		(resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent) &&
-		(statusCode == http.StatusOK || statusCode == http.StatusNoContent) {
-		return resp, nil, nil
+	return resp, nil
+}
+
+// do sends the request, coalescing GET requests when enabled.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.flight != nil && req.Method == http.MethodGet {
+		return c.coalesce(req)
 	}
 
-	b, err := io.ReadAll(resp.Body)
+	return c.fetch(req)
+}
+
+func statusError(method, contentType, endpoint string, expected, statusCode int, b []byte) error {
+	err := decodeAPIError(statusCode, b)
+
+	if err != nil {
+		return err
+	}
+
+	switch statusCode {
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %s %s", ErrForbidden, method, endpoint)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, endpoint)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: %s %s", ErrUnauthorized, method, endpoint)
+	case http.StatusUnsupportedMediaType:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	case http.StatusNotAcceptable:
+		return fmt.Errorf("%w: %s %s", ErrNotAcceptable, method, endpoint)
+	default:
+		return fmt.Errorf("%w: expected %d, got %d", ErrStatus, expected, statusCode)
+	}
+}
+
+// requestBody returns a function opening the request body of the given
+// source. Reader sources can only be opened again when seekable.
+func requestBody(contentType string, src interface{}, options []RequestOption) (func() (io.Reader, error), []byte, error) {
+	if src == nil {
+		return func() (io.Reader, error) { return nil, nil }, nil, nil
+	}
+
+	v, ok := src.(io.Reader)
+
+	if !ok {
+		payload, err := json.Marshal(src)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return func() (io.Reader, error) { return bytes.NewReader(payload), nil }, payload, nil
+	}
+
+	var (
+		offset int64
+		opened bool
+	)
+
+	seeker, seekable := v.(io.Seeker)
+
+	if seekable {
+		var err error
+		offset, err = seeker.Seek(0, io.SeekCurrent)
+		seekable = err == nil
+	}
+
+	open := func() (io.Reader, error) {
+		if opened {
+			if !seekable {
+				return nil, ErrNotReplayable
+			}
+
+			_, err := seeker.Seek(offset, io.SeekStart)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		opened = true
+
+		// Optimize sending large CSV payloads with a record limit
+		if contentType == types.CSV {
+			limit := 0
+			headers := true // Headers are assumed by default
+
+			for _, option := range options {
+				switch option.param {
+				case "limit":
+					limit = option.value.(int)
+
+				case "headers":
+					headers = option.value.(bool)
+				}
+			}
+
+			if limit > 0 {
+				if headers {
+					limit++
+				}
+
+				return csvutil.NewLineLimitReader(v, limit), nil
+			}
+		}
+
+		return v, nil
+	}
+
+	return open, nil, nil
+}
+
+// buildRequest builds a request for the opened body, compressing it unless
+// disabled. The compressed body is returned, if any.
+func (c *Client) buildRequest(ctx context.Context, method, contentType, endpoint string, open func() (io.Reader, error), nilDst, compress bool, options []RequestOption) (*http.Request, *compressedBody, error) {
+	r, err := open()
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if statusCode > 0 &&
-		resp.StatusCode != statusCode ||
-		(resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices) {
-		err := decodeAPIError(resp.StatusCode, b)
+	var body *compressedBody
+
+	if compress {
+		r, body, err = c.compressBody(r)
 
 		if err != nil {
 			return nil, nil, err
 		}
+	}
+
+	req, err := c.newRequest(ctx, method, endpoint, r)
 
-		switch resp.StatusCode {
-		case http.StatusForbidden:
-			return nil, nil, fmt.Errorf("%w: %s %s", ErrForbidden, method, endpoint)
-		case http.StatusNotFound:
-			return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, endpoint)
-		case http.StatusUnauthorized:
-			return nil, nil, fmt.Errorf("%w: %s %s", ErrUnauthorized, method, endpoint)
-		default:
-			return nil, nil, fmt.Errorf("%w: expected %d, got %d", ErrStatus, statusCode, resp.StatusCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if v, exists := findOption(options, "accept"); exists {
+		req.Header.Add("Accept", v.(string))
+	} else {
+		req.Header.Add("Accept", acceptType(contentType))
+	}
+	req.Header.Add("Content-Type", contentType)
+
+	if body != nil {
+		req.Header.Add("Content-Encoding", body.encoding)
+	}
+
+	c.acceptEncoding(req)
+
+	if nilDst {
+		switch method {
+		case http.MethodPatch, http.MethodPost, http.MethodPut:
+			req.Header.Add("No-Response-Body", "1")
 		}
 	}
 
-	if dst != nil {
-		return resp, b, json.Unmarshal(b, &dst)
+	q := req.URL.Query()
+
+	for _, option := range options {
+		option.fn(&q)
 	}
 
-	return resp, b, nil
+	req.URL.RawQuery = q.Encode()
+
+	return req, body, nil
 }
 
 // Error represents a client error.
@@ -279,6 +465,24 @@ func decodeAPIError(statusCode int, b []byte) error {
 	return e
 }
 
+// isUnsupportedMediaType reports whether the API rejected the request
+// content type.
+func isUnsupportedMediaType(err error) bool {
+	var e Error
+
+	return errors.Is(err, ErrUnsupportedMediaType) ||
+		errors.As(err, &e) && e.StatusCode == http.StatusUnsupportedMediaType
+}
+
+// isNotAcceptable reports whether the API cannot respond with the accepted
+// content type.
+func isNotAcceptable(err error) bool {
+	var e Error
+
+	return errors.Is(err, ErrNotAcceptable) ||
+		errors.As(err, &e) && e.StatusCode == http.StatusNotAcceptable
+}
+
 func nextLink(resp *http.Response) (string, error) {
 	for _, v := range linkheader.Parse(strings.TrimPrefix(resp.Header.Get("link"), "Link:")) {
 		if v.Rel != "next" {
@@ -297,6 +501,11 @@ func nextLink(resp *http.Response) (string, error) {
 	return "", nil
 }
 
-func getContentType(filename string) (string, error) {
-	return types.TypeFromExt(filepath.Ext(filename))
+func acceptType(contentType string) string {
+	switch contentType {
+	case types.JSONPatch, types.MergePatch:
+		return types.JSON
+	}
+
+	return contentType
 }
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// Generator options.
+type options struct {
+	pkg          string
+	repositories bool
+}
+
+var initialisms = map[string]bool{
+	"API": true, "CSV": true, "HTML": true, "HTTP": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+func generate(s []*schema.Schema, opts options) ([]byte, error) {
+	var (
+		buf     bytes.Buffer
+		body    bytes.Buffer
+		imports = map[string]bool{"github.com/instapi/client-go/record": true}
+	)
+
+	s = append([]*schema.Schema{}, s...)
+	sort.Slice(s, func(i, j int) bool { return s[i].Name < s[j].Name })
+
+	for _, v := range s {
+		typeName := identifier(v.Name)
+		pk := make(map[string]bool, len(v.PrimaryKey))
+		indexed := make(map[string]bool, len(v.Indexed))
+
+		for _, f := range v.PrimaryKey {
+			pk[f] = true
+		}
+
+		for _, f := range v.Indexed {
+			indexed[f] = true
+		}
+
+		fmt.Fprintf(&body, "// %sSchema is the %s schema name.\nconst %sSchema = %q\n\n", typeName, v.Name, typeName, v.Name)
+		fmt.Fprintf(&body, "// %s represents a %s record.\ntype %s struct {\n\trecord.Record\n", typeName, v.Name, typeName)
+
+		names := map[string]bool{"Record": true}
+
+		for _, f := range v.Fields {
+			format := ""
+
+			if f.Format != nil {
+				format = *f.Format
+			}
+
+			goType, path := goTypeOf(f.Type, format)
+
+			if path != "" {
+				imports[path] = true
+			}
+
+			if !f.Required && !pk[f.Name] && !isNillable(goType) {
+				goType = "*" + goType
+			}
+
+			name := identifier(f.Name)
+
+			for i := 2; names[name]; i++ {
+				name = identifier(f.Name) + strconv.Itoa(i)
+			}
+
+			names[name] = true
+
+			tag := f.Name
+
+			switch {
+			case pk[f.Name]:
+				tag += ",pk"
+
+			case f.Required:
+				tag += ",required"
+			}
+
+			if indexed[f.Name] {
+				tag += ",indexed"
+			}
+
+			if format != "" && !strings.Contains(format, ",") {
+				tag += ",format=" + format
+			}
+
+			fmt.Fprintf(&body, "\t%s %s `json:%q instapi:%q`\n", name, goType, f.Name, tag)
+		}
+
+		body.WriteString("}\n\n")
+
+		if opts.repositories {
+			imports["github.com/instapi/client-go"] = true
+
+			fmt.Fprintf(&body, "// New%sRepository creates a typed repository for the %s schema.\n", typeName, v.Name)
+			fmt.Fprintf(&body, "func New%sRepository(c *instapi.Client, account string) (*instapi.Repository[%s], error) {\n", typeName, typeName)
+			fmt.Fprintf(&body, "\treturn instapi.NewRepository[%s](c, account, %sSchema)\n}\n\n", typeName, typeName)
+		}
+	}
+
+	buf.WriteString("// Code generated by instapi-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.pkg)
+
+	paths := make([]string, 0, len(imports))
+
+	for k := range imports {
+		paths = append(paths, k)
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := isStdlib(paths[i]), isStdlib(paths[j])
+
+		if a != b {
+			return a
+		}
+
+		return paths[i] < paths[j]
+	})
+
+	buf.WriteString("import (\n")
+
+	for i, v := range paths {
+		if i > 0 && isStdlib(v) != isStdlib(paths[i-1]) {
+			buf.WriteString("\n")
+		}
+
+		fmt.Fprintf(&buf, "\t%q\n", v)
+	}
+
+	buf.WriteString(")\n\n")
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// goTypeOf returns the Go type name and import path for the field type and
+// format. Temporal values are only decoded into time.Time when in RFC 3339,
+// the only layout it accepts from JSON, and are otherwise kept as strings.
+func goTypeOf(t schema.FieldType, format string) (string, string) {
+	switch {
+	case t.IsText():
+		return "string", ""
+
+	case t.IsTemporal():
+		if t.Layout(format) != time.RFC3339 {
+			return "string", ""
+		}
+
+		return "time.Time", "time"
+	}
+
+	switch t {
+	case schema.Integer:
+		return "int64", ""
+
+	case schema.Number, schema.Decimal:
+		return "float64", ""
+
+	case schema.Boolean:
+		return "bool", ""
+
+	case schema.JSON:
+		return "json.RawMessage", "encoding/json"
+
+	case schema.Object:
+		return "map[string]interface{}", ""
+
+	case schema.Array:
+		return "[]interface{}", ""
+	}
+
+	return "interface{}", ""
+}
+
+func isStdlib(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+func isNillable(goType string) bool {
+	return strings.HasPrefix(goType, "[]") ||
+		strings.HasPrefix(goType, "map[") ||
+		goType == "json.RawMessage" ||
+		goType == "interface{}"
+}
+
+// identifier converts a schema or field name into an exported Go identifier.
+func identifier(name string) string {
+	var sb strings.Builder
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, w := range words {
+		if u := strings.ToUpper(w); initialisms[u] {
+			sb.WriteString(u)
+			continue
+		}
+
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		sb.WriteString(string(r))
+	}
+
+	s := sb.String()
+
+	if s == "" || unicode.IsDigit([]rune(s)[0]) {
+		s = "X" + s
+	}
+
+	return s
+}
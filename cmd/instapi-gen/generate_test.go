@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	for _, tt := range []struct {
+		golden string
+		names  string
+		opts   options
+	}{
+		{golden: "models.golden", opts: options{pkg: "models", repositories: true}},
+		{golden: "people.golden", names: "people", opts: options{pkg: "people"}},
+	} {
+		t.Run(tt.golden, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "models_gen.go")
+
+			require.NoError(t, run("", filepath.Join("testdata", "schemas.json"), tt.names, output, tt.opts))
+
+			b, err := os.ReadFile(output)
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", tt.golden)
+
+			if *update {
+				require.NoError(t, os.WriteFile(golden, b, 0o644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(b))
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	require.ErrorIs(t, run("", "testdata/schemas.json", "", "", options{}), errNoPackage)
+	require.ErrorIs(t, run("", "", "", "", options{pkg: "models"}), errNoSource)
+}
+
+// decodeProgram decodes a people record the way typed repositories do,
+// printing the decoded struct.
+const decodeProgram = `package main
+
+import (
+	"fmt"
+
+	"github.com/instapi/client-go/record"
+
+	"gentest/people"
+)
+
+func main() {
+	m, err := record.MappingOf(people.People{})
+
+	if err != nil {
+		panic(err)
+	}
+
+	var p people.People
+	err = m.Decode([]byte(` + "`" + `{
+		"instapi:id": "1",
+		"instapi:createdAt": "2021-01-02T03:04:05Z",
+		"id": 1,
+		"full-name": "Jane Doe",
+		"born": "24/12/1990",
+		"joined": "2020-03-01",
+		"wakes": "07:30:00",
+		"last_seen": "2021-02-03T04:05:06Z",
+		"2fa": true,
+		"address": {"city": "Auckland"}
+	}` + "`" + `), &p)
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(p.ID, p.FullName, *p.Born, *p.Joined, *p.Wakes, p.LastSeen.UTC().Format("2006-01-02 15:04:05"), p.X2fa, p.Address["city"])
+}
+`
+
+// TestGenerateDecode compiles the generated people golden file and decodes a
+// record with it.
+func TestGenerateDecode(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+
+	if err != nil {
+		t.Skip("skipping because go not found")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "people.golden"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	mod := "module gentest\n\ngo 1.18\n\nrequire github.com/instapi/client-go v0.0.0\n\nreplace github.com/instapi/client-go => " + root + "\n"
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "people"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "people", "people_gen.go"), golden, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(decodeProgram), 0o644))
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off", "GOTOOLCHAIN=local")
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.Equal(t, "1 Jane Doe 24/12/1990 2020-03-01 07:30:00 2021-02-03 04:05:06 true Auckland\n", string(out))
+}
@@ -0,0 +1,121 @@
+// Command instapi-gen generates Go structs and typed repositories from Instapi
+// schemas, either fetched from an account or read from local JSON/YAML files.
+//
+// Usage with go generate:
+//
+//	//go:generate instapi-gen -account acme -package models -o models_gen.go
+//	//go:generate instapi-gen -file schemas.yaml -package models -o models_gen.go
+//
+// The API endpoint and token are read from the API_ENDPOINT and TOKEN
+// environment variables when fetching schemas from an account.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/instapi/client-go"
+	"github.com/instapi/client-go/schema"
+)
+
+// Command errors.
+var (
+	errNoPackage = errors.New("package name required")
+	errNoSource  = errors.New("one of -account or -file required")
+)
+
+func main() {
+	var (
+		account      = flag.String("account", "", "account to fetch schemas from")
+		file         = flag.String("file", "", "comma separated JSON/YAML schema files")
+		names        = flag.String("schema", "", "comma separated schema names to generate (default all)")
+		pkg          = flag.String("package", os.Getenv("GOPACKAGE"), "generated package name")
+		output       = flag.String("o", "", "output file (default stdout)")
+		repositories = flag.Bool("repositories", true, "generate typed repository constructors")
+	)
+
+	flag.Parse()
+
+	err := run(*account, *file, *names, *output, options{pkg: *pkg, repositories: *repositories})
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "instapi-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(account, file, names, output string, opts options) error {
+	if opts.pkg == "" {
+		return errNoPackage
+	}
+
+	s, err := load(account, file)
+
+	if err != nil {
+		return err
+	}
+
+	if names != "" {
+		s = filter(s, strings.Split(names, ","))
+	}
+
+	b, err := generate(s, opts)
+
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(b)
+
+		return err
+	}
+
+	return os.WriteFile(output, b, 0o644) // nolint: gosec
+}
+
+func load(account, file string) ([]*schema.Schema, error) {
+	switch {
+	case file != "":
+		var s []*schema.Schema
+
+		for _, v := range strings.Split(file, ",") {
+			n, err := schema.ReadFile(v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			s = append(s, n...)
+		}
+
+		return s, nil
+
+	case account != "":
+		return instapi.Default().GetAllSchemas(context.Background(), account)
+	}
+
+	return nil, errNoSource
+}
+
+func filter(s []*schema.Schema, names []string) []*schema.Schema {
+	keep := make(map[string]bool, len(names))
+
+	for _, v := range names {
+		keep[strings.TrimSpace(v)] = true
+	}
+
+	var dst []*schema.Schema
+
+	for _, v := range s {
+		if keep[v.Name] {
+			dst = append(dst, v)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,151 @@
+package instapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CoalesceRequests option deduplicates concurrent identical GET requests, as
+// identified by URL, authorization and accepted content type. Only the first
+// request is sent, and every caller receives its own copy of the response.
+// Callers return when their context is done, and the request is canceled once
+// no caller waits for it.
+func CoalesceRequests() ClientOption {
+	return func(c *Client) {
+		c.flight = &flightGroup{}
+	}
+}
+
+// flightGroup tracks the in-flight requests.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall represents an in-flight request.
+type flightCall struct {
+	done    chan struct{}
+	resp    *http.Response
+	body    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// coalesce sends the request unless an identical request is in flight,
+// returning a copy of the shared response.
+func (c *Client) coalesce(req *http.Request) (*http.Response, error) {
+	var (
+		g   = c.flight
+		ctx = req.Context()
+		key = c.cacheKey(req)
+	)
+
+	g.mu.Lock()
+
+	if g.calls == nil {
+		g.calls = map[string]*flightCall{}
+	}
+
+	call, exists := g.calls[key]
+
+	if !exists {
+		callCtx, cancel := context.WithCancel(detachedContext{ctx})
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			defer close(call.done)
+			defer cancel()
+
+			call.resp, call.body, call.err = c.fetchAll(req.WithContext(callCtx))
+
+			g.mu.Lock()
+			g.remove(key, call)
+			g.mu.Unlock()
+		}()
+	}
+
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+
+		resp := *call.resp
+		resp.Header = call.resp.Header.Clone()
+		resp.Body = io.NopCloser(bytes.NewReader(call.body))
+		resp.ContentLength = int64(len(call.body))
+		resp.Request = req
+
+		return &resp, nil
+
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+
+		if call.waiters == 0 {
+			call.cancel()
+			g.remove(key, call)
+		}
+
+		g.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// remove removes the call unless replaced already.
+func (g *flightGroup) remove(key string, call *flightCall) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+// fetchAll sends the request, reading the whole response body.
+func (c *Client) fetchAll(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := c.fetch(req)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer resp.Body.Close() // nolint: errcheck
+
+	b, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, b, nil
+}
+
+// detachedContext keeps the values of its parent, but not its cancellation,
+// so a shared request outlives the caller which started it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
@@ -0,0 +1,115 @@
+package instapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// waiters returns the number of callers waiting for the in-flight requests.
+func (g *flightGroup) waiters() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	n := 0
+
+	for _, v := range g.calls {
+		n += v.waiters
+	}
+
+	return n
+}
+
+func TestCoalesceRequests(t *testing.T) {
+	var (
+		hits     int32
+		release  = make(chan struct{})
+		canceled = make(chan struct{})
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+
+		select {
+		case <-release:
+			_, _ = w.Write([]byte(`{"name": "people"}`))
+
+		case <-r.Context().Done():
+			close(canceled)
+		}
+	}))
+	defer srv.Close()
+
+	const n = 10
+
+	var (
+		c       = New(Endpoint(srv.URL+"/"), CoalesceRequests())
+		wg      sync.WaitGroup
+		names   = make([]string, n)
+		ctx, cf = context.WithCancel(context.Background())
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			s, err := c.GetSchema(context.Background(), "acme", "people")
+
+			if err == nil {
+				names[i] = s.Name
+				s.Name = "modified"
+			}
+		}(i)
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		_, err := c.GetSchema(ctx, "acme", "people")
+		errs <- err
+	}()
+
+	require.Eventually(t, func() bool { return c.flight.waiters() == n+1 }, time.Second, time.Millisecond)
+
+	// A canceled caller returns without affecting the others
+	cf()
+	require.ErrorIs(t, <-errs, context.Canceled)
+	require.Equal(t, n, c.flight.waiters())
+
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), hits)
+
+	for _, v := range names {
+		require.Equal(t, "people", v)
+	}
+
+	// The request is canceled once no caller waits for it
+	atomic.StoreInt32(&hits, 0)
+	release = make(chan struct{})
+	ctx, cf = context.WithCancel(context.Background())
+
+	go func() {
+		_, err := c.GetSchema(ctx, "acme", "people")
+		errs <- err
+	}()
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&hits) == 1 }, time.Second, time.Millisecond)
+	cf()
+	require.ErrorIs(t, <-errs, context.Canceled)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("request not canceled")
+	}
+}
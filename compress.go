@@ -0,0 +1,308 @@
+package instapi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content encoding names.
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate"
+	Zstd    = "zstd"
+)
+
+// replayLimit is the amount of a compressed request body that cannot be
+// opened again which is kept to retry the request uncompressed.
+const replayLimit = 1 << 20
+
+// Compression related errors.
+var (
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+	ErrNotReplayable       = errors.New("request body cannot be replayed")
+
+	errRetryUncompressed = errors.New("retrying uncompressed")
+)
+
+// Encoding represents a content encoding used for request compression and
+// response decompression.
+type Encoding struct {
+	Name      string
+	NewWriter func(io.Writer) (io.WriteCloser, error)
+	NewReader func(io.Reader) (io.ReadCloser, error)
+}
+
+var defaultEncodings = []*Encoding{
+	{
+		Name: Gzip,
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	{
+		Name: Deflate,
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	},
+	{
+		Name: Zstd,
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			br := bufio.NewReader(r)
+
+			// Reported as gzip does for empty bodies
+			_, err := br.Peek(1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+
+			if err != nil {
+				return nil, err
+			}
+
+			return zr.IOReadCloser(), nil
+		},
+	},
+}
+
+type compression struct {
+	algorithm string
+	minSize   int
+	encodings map[string]*Encoding
+}
+
+// CompressRequests option compresses request bodies of at least minSize bytes
+// with the given algorithm, gzip, deflate and zstd being supported by default.
+// When the API rejects the encoding with 415 Unsupported Media Type, the
+// request is retried uncompressed. Reader bodies which cannot be seeked are
+// retried from the first MiB read, failing with ErrNotReplayable beyond.
+func CompressRequests(algorithm string, minSize int) ClientOption {
+	return func(c *Client) {
+		c.compression().algorithm = algorithm
+		c.compression().minSize = minSize
+	}
+}
+
+// ContentEncoding option registers an additional content encoding, e.g. brotli,
+// for request compression and response decompression, replacing a default
+// encoding of the same name.
+func ContentEncoding(e *Encoding) ClientOption {
+	return func(c *Client) {
+		c.compression().encodings[e.Name] = e
+	}
+}
+
+func (c *Client) compression() *compression {
+	if c.compress == nil {
+		c.compress = &compression{encodings: map[string]*Encoding{}}
+
+		for _, v := range defaultEncodings {
+			c.compress.encodings[v.Name] = v
+		}
+	}
+
+	return c.compress
+}
+
+// compressBody compresses the given body when enabled and at least the
+// minimum size, returning nil when left uncompressed.
+func (c *Client) compressBody(r io.Reader) (io.Reader, *compressedBody, error) {
+	if r == nil || c.compress == nil || c.compress.algorithm == "" {
+		return r, nil, nil
+	}
+
+	e, exists := c.compress.encodings[c.compress.algorithm]
+
+	if !exists {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, c.compress.algorithm)
+	}
+
+	body := &compressedBody{
+		encoding: e.Name,
+		src:      r,
+		done:     make(chan struct{}),
+	}
+	r = io.TeeReader(r, &body.read)
+
+	buf := make([]byte, c.compress.minSize)
+	n, err := io.ReadFull(r, buf)
+
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return bytes.NewReader(buf[:n]), nil, nil
+
+	case err != nil:
+		return nil, nil, err
+	}
+
+	pr, pw := io.Pipe()
+	w, err := e.NewWriter(pw)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body.PipeReader = pr
+
+	go func() {
+		defer close(body.done)
+
+		_, err := io.Copy(w, io.MultiReader(bytes.NewReader(buf), r))
+
+		if err == nil {
+			err = w.Close()
+		}
+
+		pw.CloseWithError(err) // nolint: errcheck
+	}()
+
+	return body, body, nil
+}
+
+// compressedBody is a request body compressed on the fly, keeping the start
+// of its source to retry the request uncompressed.
+type compressedBody struct {
+	*io.PipeReader
+	encoding string
+	src      io.Reader
+	read     replayBuffer
+	done     chan struct{}
+}
+
+// uncompressed stops the compression and returns a function opening the body
+// uncompressed, from the data read so far when the source cannot be opened
+// again.
+func (b *compressedBody) uncompressed(open func() (io.Reader, error)) func() (io.Reader, error) {
+	b.CloseWithError(errRetryUncompressed) // nolint: errcheck
+	<-b.done
+
+	return func() (io.Reader, error) {
+		r, err := open()
+
+		if errors.Is(err, ErrNotReplayable) && !b.read.truncated {
+			return io.MultiReader(bytes.NewReader(b.read.Bytes()), b.src), nil
+		}
+
+		return r, err
+	}
+}
+
+// replayBuffer is a buffer of up to replayLimit bytes, the excess being
+// dropped.
+type replayBuffer struct {
+	bytes.Buffer
+	truncated bool
+}
+
+func (b *replayBuffer) Write(p []byte) (int, error) {
+	if b.truncated || b.Len()+len(p) > replayLimit {
+		b.truncated = true
+		b.Reset()
+
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
+
+func (c *Client) acceptEncoding(req *http.Request) {
+	if c.compress == nil || len(c.compress.encodings) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(c.compress.encodings))
+
+	for k := range c.compress.encodings {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+	req.Header.Set("Accept-Encoding", strings.Join(names, ", "))
+}
+
+// decompressBody wraps the response body with a decoder for its content
+// encoding, if known.
+func (c *Client) decompressBody(resp *http.Response) error {
+	encoding := strings.TrimSpace(strings.ToLower(resp.Header.Get("Content-Encoding")))
+
+	if encoding == "" || encoding == "identity" {
+		return nil
+	}
+
+	e := c.encoding(encoding)
+
+	if e == nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+	}
+
+	r, err := e.NewReader(resp.Body)
+
+	// Empty bodies, e.g. of 204 responses, are left as is
+	if err == io.EOF {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body = &decodedBody{ReadCloser: r, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+
+	return nil
+}
+
+// encoding returns the registered or default content encoding of the given
+// name, or nil if unknown.
+func (c *Client) encoding(name string) *Encoding {
+	if c.compress != nil {
+		return c.compress.encodings[name]
+	}
+
+	for _, v := range defaultEncodings {
+		if v.Name == name {
+			return v
+		}
+	}
+
+	return nil
+}
+
+type decodedBody struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (d *decodedBody) Close() error {
+	err := d.ReadCloser.Close()
+
+	if err2 := d.body.Close(); err == nil {
+		err = err2
+	}
+
+	return err
+}
@@ -0,0 +1,146 @@
+package instapi
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/instapi/client-go/types"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeBody(t *testing.T, r *http.Request) []byte {
+	var body io.Reader = r.Body
+
+	switch r.Header.Get("Content-Encoding") {
+	case Gzip:
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+
+		body = zr
+
+	case Deflate:
+		body = flate.NewReader(r.Body)
+
+	case Zstd:
+		zr, err := zstd.NewReader(r.Body)
+		require.NoError(t, err)
+
+		defer zr.Close()
+
+		body = zr
+	}
+
+	b, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestCompressRequests(t *testing.T) {
+	var (
+		encodings []string
+		bodies    []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		bodies = append(bodies, string(decodeBody(t, r)))
+
+		// Respond with the request encoding
+		switch r.Header.Get("Content-Encoding") {
+		case Zstd:
+			var buf bytes.Buffer
+
+			zw, _ := zstd.NewWriter(&buf)
+			_, _ = zw.Write([]byte(`{"ok":true}`))
+			_ = zw.Close()
+
+			w.Header().Set("Content-Encoding", Zstd)
+			_, _ = w.Write(buf.Bytes())
+
+		default:
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	payload := strings.Repeat(`{"name":"foo","country":"NZ"},`, 1000)
+
+	for _, encoding := range []string{Gzip, Deflate, Zstd} {
+		t.Run(encoding, func(t *testing.T) {
+			encodings, bodies = nil, nil
+			c := New(Endpoint(srv.URL+"/"), CompressRequests(encoding, 1024))
+
+			var dst map[string]bool
+
+			_, _, err := c.doRequest(context.Background(), http.MethodPost, types.JSON, srv.URL+"/", http.StatusOK, strings.NewReader(payload), &dst)
+			require.NoError(t, err)
+			require.True(t, dst["ok"])
+
+			// Bodies under the minimum size are sent as is
+			_, _, err = c.doRequest(context.Background(), http.MethodPost, types.JSON, srv.URL+"/", http.StatusOK, strings.NewReader("[]"), nil)
+			require.NoError(t, err)
+
+			require.Equal(t, []string{encoding, ""}, encodings)
+			require.Equal(t, []string{payload, "[]"}, bodies)
+		})
+	}
+}
+
+func TestCompressRequestsUnsupported(t *testing.T) {
+	var (
+		encodings []string
+		bodies    []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+
+		if r.Header.Get("Content-Encoding") != "" {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+
+			return
+		}
+
+		bodies = append(bodies, string(decodeBody(t, r)))
+	}))
+	defer srv.Close()
+
+	var (
+		c       = New(Endpoint(srv.URL+"/"), CompressRequests(Zstd, 0))
+		payload = strings.Repeat("a,b,c\n", 1000)
+	)
+
+	// Readers which cannot be seeked are retried from the data read
+	for i := 0; i < 2; i++ {
+		_, _, err := c.doRequest(context.Background(), http.MethodPost, types.CSV, srv.URL+"/", http.StatusOK, io.MultiReader(strings.NewReader(payload)), nil)
+		require.NoError(t, err)
+	}
+
+	// Compression is only disabled for the failing requests
+	require.Equal(t, []string{Zstd, "", Zstd, ""}, encodings)
+	require.Equal(t, []string{payload, payload}, bodies)
+
+	// Unless too large
+	encodings, bodies = nil, nil
+	payload = strings.Repeat("a,b,c\n", replayLimit/6+1)
+
+	_, _, err := c.doRequest(context.Background(), http.MethodPost, types.CSV, srv.URL+"/", http.StatusOK, io.MultiReader(strings.NewReader(payload)), nil)
+	require.ErrorIs(t, err, ErrNotReplayable)
+
+	// Whereas seekable readers are opened again
+	_, _, err = c.doRequest(context.Background(), http.MethodPost, types.CSV, srv.URL+"/", http.StatusOK, strings.NewReader(payload), nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{Zstd, Zstd, ""}, encodings)
+	require.Equal(t, []string{payload}, bodies)
+}
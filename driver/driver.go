@@ -0,0 +1,311 @@
+// Package driver implements a read only database/sql driver backed by the
+// Instapi query API. It registers itself as "instapi":
+//
+//	db, err := sql.Open("instapi", "https://TOKEN@api.instapi.com/v1/?account=acme")
+//
+// The DSN is the API endpoint URL holding the token as user information and
+// the account and SQL dialect as the account and dialect parameters. The
+// instapi scheme is an alias of https.
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	instapi "github.com/instapi/client-go"
+	"github.com/instapi/client-go/schema"
+)
+
+// DriverName is the name the driver is registered with.
+const DriverName = "instapi"
+
+// Driver errors.
+var (
+	ErrInvalidDSN         = errors.New("invalid instapi DSN")
+	ErrReadOnly           = errors.New("instapi driver is read only")
+	ErrMixedArgs          = errors.New("mixed positional and named arguments")
+	ErrNoTransactions     = errors.New("instapi driver does not support transactions")
+	ErrUnexpectedResponse = errors.New("unexpected query response")
+)
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+// Driver implements the driver.Driver and driver.DriverContext interfaces.
+type Driver struct{}
+
+// Open returns a new connection for the given DSN.
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	c, err := d.OpenConnector(dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Connect(context.Background())
+}
+
+// OpenConnector parses the DSN and returns a connector sharing a client.
+func (d *Driver) OpenConnector(dsn string) (driver.Connector, error) {
+	cfg, err := ParseDSN(dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnector(cfg), nil
+}
+
+// Config represents a parsed DSN.
+type Config struct {
+	Endpoint string
+	Token    string
+	Account  string
+	Dialect  schema.Dialect
+}
+
+// ParseDSN parses a DSN of the form
+// https://TOKEN@host/path/?account=name&dialect=sqlite.
+func ParseDSN(dsn string) (*Config, error) {
+	u, err := url.Parse(dsn)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDSN, err)
+	}
+
+	switch u.Scheme {
+	case "instapi":
+		u.Scheme = "https"
+
+	case "http", "https":
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidDSN, u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: missing host", ErrInvalidDSN)
+	}
+
+	cfg := &Config{}
+
+	if u.User != nil {
+		cfg.Token = u.User.Username()
+
+		if password, ok := u.User.Password(); ok && cfg.Token == "" {
+			cfg.Token = password
+		}
+	}
+
+	q := u.Query()
+	cfg.Account = q.Get("account")
+	cfg.Dialect = schema.Dialect(q.Get("dialect"))
+
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	if u.Path == "" || u.Path[len(u.Path)-1] != '/' {
+		u.Path += "/"
+	}
+
+	cfg.Endpoint = u.String()
+
+	return cfg, nil
+}
+
+// Connector implements the driver.Connector interface.
+type Connector struct {
+	client  *instapi.Client
+	account string
+}
+
+// NewConnector returns a connector for the given configuration, which can be
+// passed to sql.OpenDB. Additional client options, e.g. HTTPClient, are
+// applied after the configuration.
+func NewConnector(cfg *Config, options ...instapi.ClientOption) *Connector {
+	opts := append([]instapi.ClientOption{instapi.Endpoint(cfg.Endpoint)}, options...)
+
+	if cfg.Token != "" {
+		opts = append(opts, instapi.Token(cfg.Token))
+	}
+
+	if cfg.Dialect != "" {
+		opts = append(opts, instapi.SQLDialect(cfg.Dialect))
+	}
+
+	return &Connector{client: instapi.New(opts...), account: cfg.Account}
+}
+
+// Connect returns a connection.
+func (c *Connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{client: c.client, account: c.account}, nil
+}
+
+// Driver returns the underlying driver.
+func (c *Connector) Driver() driver.Driver {
+	return &Driver{}
+}
+
+// conn implements the driver.Conn, driver.QueryerContext and driver.Pinger
+// interfaces. Connections are stateless HTTP clients.
+type conn struct {
+	client  *instapi.Client
+	account string
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, ErrNoTransactions
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return nil, ErrNoTransactions
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	_, err := c.client.Account(ctx)
+
+	if isTransportError(ctx, err) {
+		return driver.ErrBadConn
+	}
+
+	return err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	var (
+		b       json.RawMessage
+		options []instapi.RequestOption
+	)
+
+	if c.account != "" {
+		options = append(options, instapi.Account(c.account))
+	}
+
+	positional, named, err := splitArgs(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if named != nil {
+		err = c.client.QueryNamed(ctx, query, &b, named, options...)
+	} else {
+		err = c.client.Query(ctx, query, &b, positional, options...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeRows(b)
+}
+
+func (c *conn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, ErrReadOnly
+}
+
+// CheckNamedValue accepts any value, leaving its encoding to the client.
+func (c *conn) CheckNamedValue(v *driver.NamedValue) error {
+	if valuer, ok := v.Value.(driver.Valuer); ok {
+		value, err := valuer.Value()
+
+		if err != nil {
+			return err
+		}
+
+		v.Value = value
+	}
+
+	return nil
+}
+
+// isTransportError reports whether err is a network failure reaching the API,
+// as opposed to an API error or the context being done.
+func isTransportError(ctx context.Context, err error) bool {
+	var netErr net.Error
+
+	return err != nil && ctx.Err() == nil && errors.As(err, &netErr)
+}
+
+func splitArgs(args []driver.NamedValue) ([]interface{}, map[string]interface{}, error) {
+	var (
+		positional []interface{}
+		named      map[string]interface{}
+	)
+
+	for _, v := range args {
+		if v.Name == "" {
+			positional = append(positional, v.Value)
+			continue
+		}
+
+		if named == nil {
+			named = map[string]interface{}{}
+		}
+
+		named[v.Name] = v.Value
+	}
+
+	if len(positional) > 0 && named != nil {
+		return nil, nil, ErrMixedArgs
+	}
+
+	return positional, named, nil
+}
+
+// stmt implements the driver.Stmt interface. Statements are not prepared
+// server side.
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *stmt) ExecContext(context.Context, []driver.NamedValue) (driver.Result, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return s.QueryContext(context.Background(), named)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
@@ -0,0 +1,141 @@
+package driver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	instapi "github.com/instapi/client-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDSN(t *testing.T) {
+	cfg, err := ParseDSN("instapi://secret@api.instapi.com/v1?account=acme&dialect=postgres")
+
+	require.NoError(t, err)
+	require.Equal(t, &Config{
+		Endpoint: "https://api.instapi.com/v1/",
+		Token:    "secret",
+		Account:  "acme",
+		Dialect:  "postgres",
+	}, cfg)
+
+	_, err = ParseDSN("mysql://user@host/db")
+	require.ErrorIs(t, err, ErrInvalidDSN)
+
+	_, err = ParseDSN("https:///v1/")
+	require.ErrorIs(t, err, ErrInvalidDSN)
+}
+
+func TestQuery(t *testing.T) {
+	var requests []*http.Request
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(b)))
+		requests = append(requests, r)
+
+		var req struct {
+			Args []interface{} `json:"args"`
+		}
+
+		require.NoError(t, json.Unmarshal(b, &req))
+		require.Equal(t, []interface{}{"b"}, req.Args)
+
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "name": "a", "score": 1, "tags": ["x"], "active": true},
+			{"id": 2, "name": null, "score": 2.5, "tags": null, "active": false, "extra": "e"}
+		]`))
+	}))
+	defer srv.Close()
+
+	db, err := sql.Open(DriverName, strings.Replace(srv.URL, "http://", "http://secret@", 1)+"/v1/?account=acme")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	rows, err := db.Query("SELECT * FROM people WHERE name > ?", "b")
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	require.Len(t, requests, 1)
+	require.Equal(t, "/v1/query", requests[0].URL.Path)
+	require.Equal(t, "acme", requests[0].URL.Query().Get("account"))
+	require.Equal(t, "Bearer secret", requests[0].Header.Get("Authorization"))
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name", "score", "tags", "active", "extra"}, columns)
+
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+
+	var names []string
+
+	for _, v := range types {
+		names = append(names, v.DatabaseTypeName())
+	}
+
+	require.Equal(t, []string{"INTEGER", "TEXT", "REAL", "JSON", "BOOLEAN", "TEXT"}, names)
+
+	nullable, ok := types[1].Nullable()
+	require.True(t, ok)
+	require.True(t, nullable)
+
+	var (
+		id     int64
+		name   sql.NullString
+		score  float64
+		tags   []byte
+		active bool
+		extra  sql.NullString
+	)
+
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&id, &name, &score, &tags, &active, &extra))
+	require.Equal(t, int64(1), id)
+	require.Equal(t, "a", name.String)
+	require.Equal(t, 1.0, score)
+	require.JSONEq(t, `["x"]`, string(tags))
+	require.True(t, active)
+	require.False(t, extra.Valid)
+
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&id, &name, &score, &tags, &active, &extra))
+	require.False(t, name.Valid)
+	require.Equal(t, 2.5, score)
+	require.Equal(t, "e", extra.String)
+
+	require.False(t, rows.Next())
+	require.NoError(t, rows.Err())
+
+	_, err = db.Exec("DELETE FROM people")
+	require.ErrorIs(t, err, ErrReadOnly)
+}
+
+func TestPing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	db, err := sql.Open(DriverName, strings.Replace(srv.URL, "http://", "http://secret@", 1)+"/v1/")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	err = db.Ping()
+
+	require.ErrorIs(t, err, instapi.ErrUnauthorized)
+
+	srv.Close()
+
+	err = db.Ping()
+
+	require.ErrorIs(t, err, driver.ErrBadConn)
+}
@@ -0,0 +1,276 @@
+package driver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Database type names reported for result columns.
+const (
+	typeInteger = "INTEGER"
+	typeReal    = "REAL"
+	typeBoolean = "BOOLEAN"
+	typeText    = "TEXT"
+	typeJSON    = "JSON"
+	typeNull    = "NULL"
+)
+
+var scanTypes = map[string]reflect.Type{
+	typeInteger: reflect.TypeOf(int64(0)),
+	typeReal:    reflect.TypeOf(float64(0)),
+	typeBoolean: reflect.TypeOf(false),
+	typeText:    reflect.TypeOf(""),
+	typeJSON:    reflect.TypeOf([]byte(nil)),
+	typeNull:    reflect.TypeOf((*interface{})(nil)).Elem(),
+}
+
+// rows implements the driver.Rows interface and the column type extensions
+// over a decoded JSON array of objects. Column types are derived from the
+// values of every row.
+type rows struct {
+	columns  []string
+	types    []string
+	nullable []bool
+	values   [][]interface{}
+	next     int
+}
+
+// decodeRows decodes a JSON array of objects, preserving the key order of
+// first appearance as the column order.
+func decodeRows(b []byte) (*rows, error) {
+	r := &rows{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	t, err := dec.Token()
+
+	if err == io.EOF {
+		return r, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty result may be null
+	if t == nil {
+		return r, nil
+	}
+
+	if t != json.Delim('[') {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, t)
+	}
+
+	index := map[string]int{}
+
+	for dec.More() {
+		keys, values, err := decodeObject(dec)
+
+		if err != nil {
+			return nil, err
+		}
+
+		row := make([]interface{}, len(r.columns), len(r.columns)+len(keys))
+
+		for i, k := range keys {
+			j, exists := index[k]
+
+			if !exists {
+				j = len(r.columns)
+				index[k] = j
+				r.columns = append(r.columns, k)
+				row = append(row, nil)
+			}
+
+			row[j] = values[i]
+		}
+
+		r.values = append(r.values, row)
+	}
+
+	r.resolveTypes()
+
+	return r, nil
+}
+
+// decodeObject decodes the next JSON object, returning its keys in order
+// along with their values.
+func decodeObject(dec *json.Decoder) ([]string, []interface{}, error) {
+	t, err := dec.Token()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t != json.Delim('{') {
+		return nil, nil, fmt.Errorf("%w: row %v", ErrUnexpectedResponse, t)
+	}
+
+	var (
+		keys   []string
+		values []interface{}
+	)
+
+	for dec.More() {
+		t, err = dec.Token()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		k, _ := t.(string)
+
+		var v interface{}
+
+		if err = dec.Decode(&v); err != nil {
+			return nil, nil, err
+		}
+
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	_, err = dec.Token()
+
+	return keys, values, err
+}
+
+// resolveTypes derives the column types and converts the values to them.
+func (r *rows) resolveTypes() {
+	r.types = make([]string, len(r.columns))
+	r.nullable = make([]bool, len(r.columns))
+
+	for i := range r.columns {
+		t := typeNull
+
+		for _, row := range r.values {
+			if i >= len(row) || row[i] == nil {
+				r.nullable[i] = true
+				continue
+			}
+
+			t = widen(t, valueType(row[i]))
+		}
+
+		r.types[i] = t
+
+		for j, row := range r.values {
+			if i < len(row) {
+				r.values[j][i] = convert(row[i], t)
+			}
+		}
+	}
+}
+
+func valueType(v interface{}) string {
+	switch v := v.(type) {
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return typeInteger
+		}
+
+		return typeReal
+
+	case bool:
+		return typeBoolean
+
+	case string:
+		return typeText
+	}
+
+	return typeJSON
+}
+
+func widen(a, b string) string {
+	switch {
+	case a == typeNull || a == b:
+		return b
+
+	case (a == typeInteger && b == typeReal) || (a == typeReal && b == typeInteger):
+		return typeReal
+	}
+
+	return typeText
+}
+
+func convert(v interface{}, t string) driver.Value {
+	switch v := v.(type) {
+	case nil:
+		return nil
+
+	case json.Number:
+		switch t {
+		case typeInteger:
+			n, _ := v.Int64()
+			return n
+
+		case typeReal:
+			f, _ := v.Float64()
+			return f
+		}
+
+		return v.String()
+
+	case bool:
+		if t == typeBoolean {
+			return v
+		}
+
+		return fmt.Sprint(v)
+
+	case string:
+		return v
+	}
+
+	b, _ := json.Marshal(v)
+
+	if t == typeJSON {
+		return b
+	}
+
+	return string(b)
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	r.next = len(r.values)
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+
+	row := r.values[r.next]
+	r.next++
+
+	for i := range dest {
+		dest[i] = nil
+
+		if i < len(row) {
+			dest[i] = row[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.types[index]
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	return scanTypes[r.types[index]]
+}
+
+func (r *rows) ColumnTypeNullable(index int) (bool, bool) {
+	return r.nullable[index], true
+}
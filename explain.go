@@ -0,0 +1,118 @@
+package instapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// QueryPlan represents the execution plan of a query.
+type QueryPlan struct {
+	Schemas       []*SchemaPlan `json:"schemas"`
+	EstimatedRows int64         `json:"estimatedRows"`
+	EstimatedCost float64       `json:"estimatedCost,omitempty"`
+}
+
+// SchemaPlan represents the access of a schema by a query.
+type SchemaPlan struct {
+	Account string `json:"account,omitempty"`
+	Name    string `json:"name"`
+	// Fields lists the fields read.
+	Fields []string `json:"fields,omitempty"`
+	// Predicates lists the fields filtered, joined or sorted on.
+	Predicates    []string `json:"predicates,omitempty"`
+	EstimatedRows int64    `json:"estimatedRows"`
+	// Indexes lists the indexed predicate fields, resolved from the primary
+	// key and indexed fields of the schema. Indexes and FullScan are left
+	// unset when the schema cannot be resolved.
+	Indexes []string `json:"indexes,omitempty"`
+	// FullScan reports whether no index serves the predicates.
+	FullScan bool `json:"fullScan"`
+}
+
+// ExplainQuery returns the execution plan of a SQL query without executing
+// it. The indexes used are resolved by getting the schemas touched, which
+// default to the account set with the Account option, skipping schemas of no
+// known account or which cannot be got. Arguments are bound as with Query.
+func (c *Client) ExplainQuery(ctx context.Context, query string, args []interface{}, options ...RequestOption) (*QueryPlan, error) {
+	var plan QueryPlan
+
+	err := c.query(query, args, nil, func(contentType string, src interface{}) error {
+		_, _, err := c.doRequest(ctx, http.MethodPost, contentType, c.endpoint+"query/explain", http.StatusOK, src, &plan, options...)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	account := ""
+
+	if v, exists := findOption(options, "account"); exists {
+		account, _ = v.(string)
+	}
+
+	schemas := map[[2]string]*schema.Schema{}
+
+	for _, v := range plan.Schemas {
+		if v.Account == "" {
+			v.Account = account
+		}
+
+		if v.Account == "" {
+			continue
+		}
+
+		key := [2]string{v.Account, v.Name}
+		s, exists := schemas[key]
+
+		// Failed lookups are kept as nil, so that they are not repeated
+		if !exists {
+			s, err = c.GetSchema(ctx, v.Account, v.Name)
+
+			if err != nil {
+				s = nil
+			}
+
+			schemas[key] = s
+		}
+
+		if s == nil {
+			continue
+		}
+
+		v.Indexes = indexesUsed(s, v.Predicates)
+		v.FullScan = len(v.Indexes) == 0
+	}
+
+	return &plan, nil
+}
+
+// indexesUsed returns the predicate fields which are indexed or lead the
+// primary key.
+func indexesUsed(s *schema.Schema, predicates []string) []string {
+	indexed := map[string]bool{}
+
+	for _, v := range s.Indexed {
+		indexed[v] = true
+	}
+
+	if len(s.PrimaryKey) > 0 {
+		indexed[s.PrimaryKey[0]] = true
+	}
+
+	var (
+		indexes []string
+		seen    = map[string]bool{}
+	)
+
+	for _, v := range predicates {
+		if indexed[v] && !seen[v] {
+			seen[v] = true
+			indexes = append(indexes, v)
+		}
+	}
+
+	return indexes
+}
@@ -0,0 +1,119 @@
+package instapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainQuery(t *testing.T) {
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+
+		switch r.URL.Path {
+		case "/query/explain":
+			_, _ = w.Write([]byte(`{
+				"estimatedRows": 120,
+				"schemas": [
+					{"name": "people", "fields": ["id", "name"], "predicates": ["country", "id"], "estimatedRows": 100},
+					{"name": "orders", "fields": ["total"], "predicates": ["note"], "estimatedRows": 20},
+					{"account": "other", "name": "people", "estimatedRows": 1}
+				]
+			}`))
+
+		case "/accounts/acme/schemas/people", "/accounts/other/schemas/people":
+			_, _ = w.Write([]byte(`{"name": "people", "primaryKey": ["id"], "indexed": ["country"]}`))
+
+		case "/accounts/acme/schemas/orders":
+			_, _ = w.Write([]byte(`{"name": "orders", "indexed": ["customer"]}`))
+
+		case "/query":
+			require.Equal(t, "true", r.URL.Query().Get("dryRun"))
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(Endpoint(srv.URL + "/"))
+	plan, err := c.ExplainQuery(context.Background(), "SELECT * FROM people WHERE country = ?", []interface{}{"NZ"}, Account("acme"))
+
+	require.NoError(t, err)
+	require.Equal(t, int64(120), plan.EstimatedRows)
+	require.Len(t, plan.Schemas, 3)
+	require.Equal(t, "acme", plan.Schemas[0].Account)
+	require.Equal(t, []string{"country", "id"}, plan.Schemas[0].Indexes)
+	require.False(t, plan.Schemas[0].FullScan)
+	require.Empty(t, plan.Schemas[1].Indexes)
+	require.True(t, plan.Schemas[1].FullScan)
+	require.Equal(t, "other", plan.Schemas[2].Account)
+	require.True(t, plan.Schemas[2].FullScan)
+	require.Equal(t, []string{
+		"POST /query/explain",
+		"GET /accounts/acme/schemas/people",
+		"GET /accounts/acme/schemas/orders",
+		"GET /accounts/other/schemas/people",
+	}, paths)
+
+	var dst []map[string]interface{}
+
+	require.NoError(t, c.Query(context.Background(), "SELECT * FROM people", &dst, nil, DryRun()))
+	require.Nil(t, dst)
+}
+
+func TestExplainQueryUnresolved(t *testing.T) {
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+
+		switch r.URL.Path {
+		case "/query/explain":
+			_, _ = w.Write([]byte(`{
+				"estimatedRows": 10,
+				"schemas": [
+					{"name": "people", "predicates": ["id"], "estimatedRows": 10},
+					{"name": "people", "predicates": ["name"], "estimatedRows": 10}
+				]
+			}`))
+
+		case "/accounts/acme/schemas/people":
+			w.WriteHeader(http.StatusForbidden)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		c   = New(Endpoint(srv.URL + "/"))
+		ctx = context.Background()
+	)
+
+	// Schemas of no known account are not resolved
+	plan, err := c.ExplainQuery(ctx, "SELECT * FROM people WHERE id = 1", nil)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(10), plan.EstimatedRows)
+	require.Len(t, plan.Schemas, 2)
+	require.Nil(t, plan.Schemas[0].Indexes)
+	require.False(t, plan.Schemas[0].FullScan)
+	require.Equal(t, []string{"POST /query/explain"}, paths)
+
+	// Nor are schemas which cannot be got, which are only requested once
+	paths = nil
+	plan, err = c.ExplainQuery(ctx, "SELECT * FROM people WHERE id = 1", nil, Account("acme"))
+
+	require.NoError(t, err)
+	require.Nil(t, plan.Schemas[0].Indexes)
+	require.False(t, plan.Schemas[0].FullScan)
+	require.Equal(t, []string{"POST /query/explain", "GET /accounts/acme/schemas/people"}, paths)
+}
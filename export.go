@@ -0,0 +1,300 @@
+package instapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/instapi/client-go/internal/xlsx"
+	"github.com/instapi/client-go/types"
+)
+
+// headerRows is the number of rows buffered when encoding JSON results as
+// CSV or XLSX before writing the header, so that it holds the keys missing
+// from the first rows.
+const headerRows = 1000
+
+// Export errors.
+var (
+	ErrColumnsChanged = errors.New("query result columns changed")
+	ErrTooManyRows    = xlsx.ErrTooManyRows
+)
+
+// rowWriter encodes query result rows.
+type rowWriter interface {
+	write(columns []string, row []interface{}) error
+	close() error
+}
+
+// QueryToWriter performs a SQL query, writing its results to w encoded as
+// CSV, NDJSON, JSON or XLSX. The results are requested from the API in the
+// given content type, falling back to encoding JSON results client side,
+// streaming either way. Arguments are bound as with Query.
+//
+// When encoding JSON results as CSV or XLSX, the header holds the keys of the
+// first 1000 rows, failing with ErrColumnsChanged should a later row hold
+// another key. XLSX results fail with ErrTooManyRows beyond the 1,048,576
+// rows of a sheet, including the header.
+func (c *Client) QueryToWriter(ctx context.Context, query, contentType string, w io.Writer, args []interface{}, options ...RequestOption) error {
+	switch contentType {
+	case types.CSV, types.NDJSON, types.JSON, types.XLSX:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	options = options[:len(options):len(options)]
+	resp, responseType, err := c.queryStream(ctx, query, args, append(options, Accept(contentType)))
+
+	if isNotAcceptable(err) {
+		resp, responseType, err = c.queryStream(ctx, query, args, append(options, Accept(types.JSON)))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if responseType == contentType {
+		defer resp.Body.Close() // nolint: errcheck
+
+		_, err = io.Copy(w, resp.Body)
+
+		return err
+	}
+
+	rows, err := newRows(resp.Body, responseType)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck
+
+	rw, err := newRowWriter(contentType, w)
+
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		if err = rw.write(rows.columns, rows.row); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	return rw.close()
+}
+
+func newRowWriter(contentType string, w io.Writer) (rowWriter, error) {
+	switch contentType {
+	case types.CSV:
+		return &bufferedWriter{rowWriter: &csvWriter{w: csv.NewWriter(w)}}, nil
+
+	case types.XLSX:
+		xw, err := xlsx.NewWriter(w)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &bufferedWriter{rowWriter: &xlsxWriter{w: xw}}, nil
+	}
+
+	return &jsonWriter{w: bufio.NewWriter(w), array: contentType == types.JSON}, nil
+}
+
+// bufferedWriter buffers the first rows until the columns of the header are
+// known, padding the rows read before a column appeared.
+type bufferedWriter struct {
+	rowWriter
+	columns []string
+	rows    [][]interface{}
+	flushed bool
+}
+
+func (bw *bufferedWriter) write(columns []string, row []interface{}) error {
+	if bw.flushed {
+		return bw.rowWriter.write(columns, row)
+	}
+
+	bw.columns = columns
+	bw.rows = append(bw.rows, row)
+
+	if len(bw.rows) < headerRows {
+		return nil
+	}
+
+	return bw.flush()
+}
+
+func (bw *bufferedWriter) flush() error {
+	bw.flushed = true
+
+	for _, v := range bw.rows {
+		row := make([]interface{}, len(bw.columns))
+		copy(row, v)
+
+		err := bw.rowWriter.write(bw.columns, row)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	bw.rows = nil
+
+	return nil
+}
+
+func (bw *bufferedWriter) close() error {
+	if !bw.flushed {
+		err := bw.flush()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return bw.rowWriter.close()
+}
+
+// csvWriter writes CSV with a header row.
+type csvWriter struct {
+	w      *csv.Writer
+	header []string
+}
+
+func (cw *csvWriter) write(columns []string, row []interface{}) error {
+	if cw.header == nil {
+		cw.header = columns
+
+		if err := cw.w.Write(columns); err != nil {
+			return err
+		}
+	}
+
+	if len(columns) != len(cw.header) {
+		return fmt.Errorf("%w: new column %s", ErrColumnsChanged, columns[len(columns)-1])
+	}
+
+	record := make([]string, len(row))
+
+	for i, v := range row {
+		record[i] = stringValue(v)
+	}
+
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// xlsxWriter writes a workbook with a header row.
+type xlsxWriter struct {
+	w      *xlsx.Writer
+	header []string
+}
+
+func (xw *xlsxWriter) write(columns []string, row []interface{}) error {
+	if xw.header == nil {
+		xw.header = columns
+		header := make([]interface{}, len(columns))
+
+		for i, v := range columns {
+			header[i] = v
+		}
+
+		if err := xw.w.WriteRow(header); err != nil {
+			return err
+		}
+	}
+
+	if len(columns) != len(xw.header) {
+		return fmt.Errorf("%w: new column %s", ErrColumnsChanged, columns[len(columns)-1])
+	}
+
+	return xw.w.WriteRow(row)
+}
+
+func (xw *xlsxWriter) close() error {
+	return xw.w.Close()
+}
+
+// jsonWriter writes newline delimited JSON objects, or a JSON array of
+// objects, keeping the column order.
+type jsonWriter struct {
+	w     *bufio.Writer
+	array bool
+	n     int
+}
+
+func (jw *jsonWriter) write(columns []string, row []interface{}) error {
+	// Write errors are sticky and returned by the final write
+	switch {
+	case jw.array && jw.n == 0:
+		jw.w.WriteByte('[') // nolint: errcheck
+
+	case jw.array:
+		jw.w.WriteByte(',') // nolint: errcheck
+	}
+
+	jw.n++
+	jw.w.WriteByte('{') // nolint: errcheck
+
+	for i, k := range columns {
+		if i > 0 {
+			jw.w.WriteByte(',') // nolint: errcheck
+		}
+
+		var v interface{}
+
+		if i < len(row) {
+			v = row[i]
+		}
+
+		key, err := json.Marshal(k)
+
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(v)
+
+		if err != nil {
+			return err
+		}
+
+		jw.w.Write(key)     // nolint: errcheck
+		jw.w.WriteByte(':') // nolint: errcheck
+		jw.w.Write(value)   // nolint: errcheck
+	}
+
+	jw.w.WriteByte('}') // nolint: errcheck
+
+	if jw.array {
+		return nil
+	}
+
+	return jw.w.WriteByte('\n')
+}
+
+func (jw *jsonWriter) close() error {
+	switch {
+	case jw.array && jw.n == 0:
+		jw.w.WriteString("[]\n") // nolint: errcheck
+
+	case jw.array:
+		jw.w.WriteString("]\n") // nolint: errcheck
+	}
+
+	return jw.w.Flush()
+}
@@ -0,0 +1,136 @@
+package instapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/instapi/client-go/internal/xlsx"
+	"github.com/instapi/client-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryToWriter(t *testing.T) {
+	var accepted []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Get("Accept")
+		accepted = append(accepted, accept)
+
+		switch accept {
+		case types.CSV:
+			w.Header().Set("Content-Type", types.CSV)
+			_, _ = w.Write([]byte("server,csv\n"))
+
+		case types.XLSX:
+			w.WriteHeader(http.StatusNotAcceptable)
+
+		default:
+			w.Header().Set("Content-Type", types.JSON)
+			_, _ = w.Write([]byte(`[{"b": 1, "a": "x,y"}, {"b": 2.5, "a": null}]`))
+		}
+	}))
+	defer srv.Close()
+
+	c := New(Endpoint(srv.URL + "/"))
+
+	tests := []struct {
+		contentType string
+		accepted    []string
+		expected    string
+	}{
+		{types.CSV, []string{types.CSV}, "server,csv\n"},
+		{types.JSON, []string{types.JSON}, `[{"b": 1, "a": "x,y"}, {"b": 2.5, "a": null}]`},
+		{types.NDJSON, []string{types.NDJSON}, "{\"b\":1,\"a\":\"x,y\"}\n{\"b\":2.5,\"a\":null}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			accepted = nil
+
+			require.NoError(t, c.QueryToWriter(context.Background(), "SELECT * FROM t", tt.contentType, &buf, nil))
+			require.Equal(t, tt.accepted, accepted)
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	var buf bytes.Buffer
+
+	accepted = nil
+
+	require.NoError(t, c.QueryToWriter(context.Background(), "SELECT * FROM t", types.XLSX, &buf, nil))
+	require.Equal(t, []string{types.XLSX, types.JSON}, accepted)
+
+	_, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, c.QueryToWriter(context.Background(), "SELECT 1", types.Parquet, &buf, nil), ErrUnsupportedType)
+}
+
+func TestRowWriterCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	rows, err := newRows(io.NopCloser(strings.NewReader(`[{"b": 1, "a": "x,y"}, {"b": true, "a": null}]`)), types.JSON)
+	require.NoError(t, err)
+
+	rw, err := newRowWriter(types.CSV, &buf)
+	require.NoError(t, err)
+
+	for rows.Next() {
+		require.NoError(t, rw.write(rows.columns, rows.row))
+	}
+
+	require.NoError(t, rw.close())
+	require.Equal(t, "b,a\n1,\"x,y\"\ntrue,\n", buf.String())
+}
+
+func TestRowWriterColumns(t *testing.T) {
+	var buf bytes.Buffer
+
+	rows, err := newRows(io.NopCloser(strings.NewReader(`[{"a": 1}, {"a": 2, "b": "x"}, {"b": "y"}]`)), types.JSON)
+	require.NoError(t, err)
+
+	rw, err := newRowWriter(types.CSV, &buf)
+	require.NoError(t, err)
+
+	for rows.Next() {
+		require.NoError(t, rw.write(rows.columns, rows.row))
+	}
+
+	require.NoError(t, rw.close())
+	require.Equal(t, "a,b\n1,\n2,x\n,y\n", buf.String())
+
+	// Columns appearing after the header rows are an error
+	rw, err = newRowWriter(types.CSV, io.Discard)
+	require.NoError(t, err)
+
+	for i := 0; i < headerRows; i++ {
+		require.NoError(t, rw.write([]string{"a"}, []interface{}{i}))
+	}
+
+	require.ErrorIs(t, rw.write([]string{"a", "b"}, []interface{}{1, 2}), ErrColumnsChanged)
+}
+
+func TestRowWriterXLSXLimit(t *testing.T) {
+	rw, err := newRowWriter(types.XLSX, io.Discard)
+	require.NoError(t, err)
+
+	var (
+		columns = []string{"a"}
+		row     = []interface{}{1}
+	)
+
+	// The header counts towards the limit
+	for i := 0; i < xlsx.MaxRows-1; i++ {
+		require.NoError(t, rw.write(columns, row))
+	}
+
+	require.ErrorIs(t, rw.write(columns, row), ErrTooManyRows)
+}
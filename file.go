@@ -0,0 +1,304 @@
+package instapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/instapi/client-go/types"
+)
+
+// File related errors.
+var (
+	ErrEmptyArchive = errors.New("archive holds no supported files")
+)
+
+// fileFunc is called for each supported file with its name stripped of
+// extensions and its content type.
+type fileFunc func(name, contentType string, r io.Reader) error
+
+// walkFile opens the given file, decompressing .gz, .zst and .bz2 files and
+// calling fn for each supported file of .zip and .tar archives. Content types
+// are sniffed, so that files without or with a wrong extension are supported.
+func (c *Client) walkFile(filename string, fn fileFunc) error {
+	f, err := os.Open(filename) // nolint: gosec
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close() // nolint: gosec
+
+	return c.walk(filepath.Base(filename), f, fn)
+}
+
+func (c *Client) walk(name string, r io.Reader, fn fileFunc) error {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	contentType, err := fileType(ext)
+	sniffed, r, sniffErr := types.Detect(r)
+
+	switch {
+	case sniffErr != nil:
+		if err != nil {
+			return err
+		}
+
+	case err == nil:
+		contentType = resolveType(contentType, sniffed)
+
+	// Any text looks like delimited text, which is only assumed for files
+	// without an extension, e.g. temporary files
+	case ext == "" || (sniffed != types.CSV && sniffed != types.TSV):
+		contentType = sniffed
+
+	default:
+		return err
+	}
+
+	switch contentType {
+	case types.Gzip:
+		zr, err := gzip.NewReader(r)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		defer zr.Close() // nolint: errcheck
+
+		return c.walk(base, zr, fn)
+
+	case types.Bzip2:
+		return c.walk(base, bzip2.NewReader(r), fn)
+
+	case types.Zstd:
+		zr, err := c.decoder(Zstd, r)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		defer zr.Close() // nolint: errcheck
+
+		return c.walk(base, zr, fn)
+
+	case types.Tar:
+		return c.walkTar(name, tar.NewReader(r), fn)
+
+	case types.Zip:
+		return c.walkZip(name, r, fn)
+	}
+
+	return fn(base, contentType, r)
+}
+
+// fileType returns the content type for the given extension, including
+// archives and compressed files.
+func fileType(ext string) (string, error) {
+	switch strings.ToLower(ext) {
+	case ".gz", ".tgz":
+		return types.Gzip, nil
+
+	case ".bz2":
+		return types.Bzip2, nil
+
+	case ".zst":
+		return types.Zstd, nil
+
+	case ".tar":
+		return types.Tar, nil
+
+	case ".zip":
+		return types.Zip, nil
+	}
+
+	return types.TypeFromExt(ext)
+}
+
+// resolveType resolves the content type from the extension against the
+// sniffed one. Sniffing tells CSV, TSV, JSON and NDJSON apart, but text
+// content does not override other extensions, e.g. of SQL scripts.
+func resolveType(ext, sniffed string) string {
+	switch ext {
+	case types.CSV, types.TSV, types.JSON, types.NDJSON:
+		return sniffed
+	}
+
+	switch sniffed {
+	case types.CSV, types.TSV, types.JSON, types.NDJSON:
+		return ext
+
+	case types.Zip:
+		switch ext {
+		case types.XLSX, types.XLSB, types.ODS:
+			return ext
+		}
+	}
+
+	return sniffed
+}
+
+func (c *Client) walkTar(name string, tr *tar.Reader, fn fileFunc) error {
+	found := false
+
+	for {
+		h, err := tr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		if h.Typeflag != tar.TypeReg || skipEntry(h.Name) {
+			continue
+		}
+
+		err = c.walkEntry(h.Name, tr, fn)
+
+		if errors.Is(err, types.ErrUnsupportedExtension) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrEmptyArchive, name)
+	}
+
+	return nil
+}
+
+func (c *Client) walkZip(name string, r io.Reader, fn fileFunc) error {
+	ra, size, err := readerAt(r)
+
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(ra, size)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	found := false
+
+	for _, v := range zr.File {
+		if v.FileInfo().IsDir() || skipEntry(v.Name) {
+			continue
+		}
+
+		err = c.walkZipEntry(v, fn)
+
+		if errors.Is(err, types.ErrUnsupportedExtension) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrEmptyArchive, name)
+	}
+
+	return nil
+}
+
+func (c *Client) walkZipEntry(f *zip.File, fn fileFunc) error {
+	r, err := f.Open()
+
+	if err != nil {
+		return err
+	}
+
+	defer r.Close() // nolint: errcheck
+
+	return c.walkEntry(f.Name, r, fn)
+}
+
+// walkEntry walks an archive entry, which may itself be compressed but not
+// another archive.
+func (c *Client) walkEntry(name string, r io.Reader, fn fileFunc) error {
+	if isArchive(path.Base(name)) {
+		return fmt.Errorf("nested archive %s: %w", name, types.ErrUnsupportedExtension)
+	}
+
+	return c.walk(path.Base(name), r, fn)
+}
+
+func (c *Client) decoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	e := c.encoding(encoding)
+
+	if e == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+	}
+
+	return e.NewReader(r)
+}
+
+// readerAt returns an io.ReaderAt for r, buffering it unless it is a file.
+func readerAt(r io.Reader) (io.ReaderAt, int64, error) {
+	if f, ok := r.(*os.File); ok {
+		fi, err := f.Stat()
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return f, fi.Size(), nil
+	}
+
+	b, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return bytes.NewReader(b), int64(len(b)), nil
+}
+
+func isArchive(name string) bool {
+	for {
+		ext := strings.ToLower(path.Ext(name))
+
+		switch ext {
+		case ".gz", ".bz2", ".zst":
+			name = strings.TrimSuffix(name, path.Ext(name))
+			continue
+
+		case ".tar", ".tgz", ".zip":
+			return true
+		}
+
+		return false
+	}
+}
+
+// skipEntry reports whether an archive entry holds metadata, e.g. macOS
+// resource forks.
+func skipEntry(name string) bool {
+	base := path.Base(name)
+
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".")
+}
@@ -0,0 +1,197 @@
+package instapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/instapi/client-go/types"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+type walked struct {
+	name        string
+	contentType string
+	content     string
+}
+
+func walkTestFile(t *testing.T, filename string, b []byte) ([]walked, error) {
+	filename = filepath.Join(t.TempDir(), filename)
+	require.NoError(t, os.WriteFile(filename, b, 0600))
+
+	var files []walked
+	err := New().walkFile(filename, func(name, contentType string, r io.Reader) error {
+		b, err := io.ReadAll(r)
+		files = append(files, walked{name, contentType, string(b)})
+
+		return err
+	})
+
+	return files, err
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+
+	w, err := zstd.NewWriter(&buf)
+	require.NoError(t, err)
+
+	_, err = w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestWalkFileCompressed(t *testing.T) {
+	files, err := walkTestFile(t, "data.csv.gz", gzipBytes(t, []byte("a,b\n1,2\n")))
+
+	require.NoError(t, err)
+	require.Equal(t, []walked{{"data", types.CSV, "a,b\n1,2\n"}}, files)
+
+	files, err = walkTestFile(t, "data.csv.zst", zstdBytes(t, []byte("a,b\n1,2\n")))
+
+	require.NoError(t, err)
+	require.Equal(t, []walked{{"data", types.CSV, "a,b\n1,2\n"}}, files)
+
+	_, err = walkTestFile(t, "data.csv.zst", []byte{})
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = walkTestFile(t, "data.txt.gz", gzipBytes(t, []byte("text")))
+	require.ErrorIs(t, err, types.ErrUnsupportedExtension)
+}
+
+func TestWalkFileZip(t *testing.T) {
+	files, err := walkTestFile(t, "export.zip", zipBytes(t, map[string]string{
+		"companies.csv":          "id\n1\n",
+		"dir/people.json.gz":     string(gzipBytes(t, []byte(`[{"id":1}]`))),
+		"readme.txt":             "skipped",
+		"__MACOSX/._people.json": "skipped",
+	}))
+
+	require.NoError(t, err)
+	require.ElementsMatch(t, []walked{
+		{"companies", types.CSV, "id\n1\n"},
+		{"people", types.JSON, `[{"id":1}]`},
+	}, files)
+}
+
+func TestWalkFileTarGz(t *testing.T) {
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+
+	for _, v := range []struct{ name, content string }{
+		{"dump/companies.csv", "id\n1\n"},
+		{"dump/nested.zip", "skipped"},
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: v.name, Mode: 0600, Size: int64(len(v.content))}))
+		_, err := tw.Write([]byte(v.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	files, err := walkTestFile(t, "dump.tar.gz", gzipBytes(t, buf.Bytes()))
+
+	require.NoError(t, err)
+	require.Equal(t, []walked{{"companies", types.CSV, "id\n1\n"}}, files)
+
+	_, err = walkTestFile(t, "empty.tar", make([]byte, 1024))
+	require.ErrorIs(t, err, ErrEmptyArchive)
+}
+
+func TestWalkFileSniffed(t *testing.T) {
+	files, err := walkTestFile(t, "upload-1234", gzipBytes(t, []byte(`{"id":1}`+"\n"+`{"id":2}`+"\n")))
+
+	require.NoError(t, err)
+	require.Equal(t, []walked{{"upload-1234", types.NDJSON, "{\"id\":1}\n{\"id\":2}\n"}}, files)
+
+	files, err = walkTestFile(t, "data.json", []byte("id,name\n1,a\n"))
+
+	require.NoError(t, err)
+	require.Equal(t, []walked{{"data", types.CSV, "id,name\n1,a\n"}}, files)
+
+	_, err = walkTestFile(t, "blob", []byte{0x00, 0x01})
+	require.ErrorIs(t, err, types.ErrUnsupportedExtension)
+}
+
+func TestCreateRecordsFromFile(t *testing.T) {
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"count":1}`))
+	}))
+	defer srv.Close()
+
+	var (
+		c   = New(Endpoint(srv.URL + "/"))
+		dir = t.TempDir()
+	)
+
+	filename := filepath.Join(dir, "people.csv.zst")
+	require.NoError(t, os.WriteFile(filename, zstdBytes(t, []byte("id\n1\n")), 0600))
+
+	n, err := c.CreateRecordsFromFile(context.Background(), "acme", "staff", filename)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"/accounts/acme/schemas/staff/records"}, paths)
+
+	// Archive entries are created in the schema named after them
+	paths = nil
+	filename = filepath.Join(dir, "export.zip")
+	require.NoError(t, os.WriteFile(filename, zipBytes(t, map[string]string{
+		"companies.csv":   "id\n1\n",
+		"people.json.zst": string(zstdBytes(t, []byte(`[{"id":1}]`))),
+	}), 0600))
+
+	n, err = c.CreateRecordsFromFile(context.Background(), "acme", "staff", filename)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.ElementsMatch(t, []string{
+		"/accounts/acme/schemas/companies/records",
+		"/accounts/acme/schemas/people/records",
+	}, paths)
+}
@@ -0,0 +1,214 @@
+// Package filter builds record filter expressions encoded as the filter
+// query parameter of record requests:
+//
+//	filter.And(filter.Eq("country", "NZ"), filter.Gt("age", 18)).String()
+//	// and(eq(country,"NZ"),gt(age,18))
+//
+// Values are encoded as JSON, and field names as JSON strings unless they
+// are plain identifiers.
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// Filter errors.
+var (
+	ErrUnknownField = errors.New("unknown field")
+	ErrInvalidValue = errors.New("invalid filter value")
+	ErrInvalidOp    = errors.New("invalid filter operator")
+)
+
+// Expr represents a filter expression.
+type Expr interface {
+	fmt.Stringer
+	// Validate validates the expression against the schema.
+	Validate(s *schema.Schema) error
+	encode(sb *strings.Builder)
+}
+
+type comparison struct {
+	op     string
+	field  string
+	values []interface{}
+	list   bool
+}
+
+// Eq matches records with the field equal to the value.
+func Eq(field string, v interface{}) Expr {
+	return &comparison{op: "eq", field: field, values: []interface{}{v}}
+}
+
+// Ne matches records with the field not equal to the value.
+func Ne(field string, v interface{}) Expr {
+	return &comparison{op: "ne", field: field, values: []interface{}{v}}
+}
+
+// Gt matches records with the field greater than the value.
+func Gt(field string, v interface{}) Expr {
+	return &comparison{op: "gt", field: field, values: []interface{}{v}}
+}
+
+// Ge matches records with the field greater than or equal to the value.
+func Ge(field string, v interface{}) Expr {
+	return &comparison{op: "ge", field: field, values: []interface{}{v}}
+}
+
+// Lt matches records with the field less than the value.
+func Lt(field string, v interface{}) Expr {
+	return &comparison{op: "lt", field: field, values: []interface{}{v}}
+}
+
+// Le matches records with the field less than or equal to the value.
+func Le(field string, v interface{}) Expr {
+	return &comparison{op: "le", field: field, values: []interface{}{v}}
+}
+
+// In matches records with the field equal to any of the values.
+func In(field string, values ...interface{}) Expr {
+	return &comparison{op: "in", field: field, values: values, list: true}
+}
+
+// Contains matches records with a text field containing the substring, or
+// an array field containing the value.
+func Contains(field string, v interface{}) Expr {
+	return &comparison{op: "contains", field: field, values: []interface{}{v}}
+}
+
+func (c *comparison) String() string {
+	var sb strings.Builder
+	c.encode(&sb)
+
+	return sb.String()
+}
+
+func (c *comparison) Validate(s *schema.Schema) error {
+	f := s.Field(c.field)
+
+	if f == nil {
+		return fmt.Errorf("%w: %s.%s", ErrUnknownField, s.Name, c.field)
+	}
+
+	if c.list && len(c.values) == 0 {
+		return fmt.Errorf("%w: %s(%s) requires values", ErrInvalidValue, c.op, c.field)
+	}
+
+	if c.op == "contains" && !f.Type.IsText() && f.Type != schema.Array {
+		return fmt.Errorf("%w: contains on %s field %s", ErrInvalidOp, f.Type, c.field)
+	}
+
+	for _, v := range c.values {
+		if _, err := json.Marshal(v); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidValue, c.field, err)
+		}
+
+		// Null matches missing values, and array elements are of any type
+		if v == nil || c.op == "contains" && f.Type == schema.Array {
+			continue
+		}
+
+		if err := f.CheckValue(v); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidValue, c.field, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *comparison) encode(sb *strings.Builder) {
+	sb.WriteString(c.op)
+	sb.WriteByte('(')
+	sb.WriteString(encodeField(c.field))
+	sb.WriteByte(',')
+
+	if c.list {
+		sb.WriteString(encodeValue(c.values))
+	} else {
+		sb.WriteString(encodeValue(c.values[0]))
+	}
+
+	sb.WriteByte(')')
+}
+
+type logical struct {
+	op    string
+	exprs []Expr
+}
+
+// And matches records matching all the expressions.
+func And(exprs ...Expr) Expr {
+	return &logical{op: "and", exprs: exprs}
+}
+
+// Or matches records matching any of the expressions.
+func Or(exprs ...Expr) Expr {
+	return &logical{op: "or", exprs: exprs}
+}
+
+func (l *logical) String() string {
+	var sb strings.Builder
+	l.encode(&sb)
+
+	return sb.String()
+}
+
+func (l *logical) Validate(s *schema.Schema) error {
+	if len(l.exprs) == 0 {
+		return fmt.Errorf("%w: %s requires expressions", ErrInvalidValue, l.op)
+	}
+
+	for _, v := range l.exprs {
+		if err := v.Validate(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *logical) encode(sb *strings.Builder) {
+	sb.WriteString(l.op)
+	sb.WriteByte('(')
+
+	for i, v := range l.exprs {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		v.encode(sb)
+	}
+
+	sb.WriteByte(')')
+}
+
+// encodeField returns the field name as is when it is a plain identifier,
+// otherwise as a JSON string.
+func encodeField(name string) string {
+	for i, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return encodeValue(name)
+		}
+	}
+
+	if name == "" {
+		return `""`
+	}
+
+	return name
+}
+
+func encodeValue(v interface{}) string {
+	b, err := json.Marshal(v)
+
+	// Invalid values are reported by Validate
+	if err != nil {
+		return "null"
+	}
+
+	return string(b)
+}
@@ -0,0 +1,61 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/instapi/client-go/schema"
+	"github.com/stretchr/testify/require"
+)
+
+var people = &schema.Schema{
+	Name: "people",
+	Fields: []*schema.Field{
+		{Name: "name", Type: schema.String},
+		{Name: "age", Type: schema.Integer},
+		{Name: "tags", Type: schema.Array},
+		{Name: "first name", Type: schema.String},
+		{Name: "born", Type: schema.Date},
+		{Name: "id", Type: schema.UUID},
+	},
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		expr     Expr
+		expected string
+	}{
+		{Eq("name", "a"), `eq(name,"a")`},
+		{Ne("age", nil), `ne(age,null)`},
+		{In("age", 1, 2), `in(age,[1,2])`},
+		{Lt("born", "2001-02-03"), `lt(born,"2001-02-03")`},
+		{Contains("first name", `a"),or(`), `contains("first name","a\"),or(")`},
+		{
+			And(Gt("age", 18), Or(Lt("age", 65), Contains("tags", "x")), Ge("age", 1), Le("age", 2)),
+			`and(gt(age,18),or(lt(age,65),contains(tags,"x")),ge(age,1),le(age,2))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.expr.String())
+			require.NoError(t, tt.expr.Validate(people))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.ErrorIs(t, Eq("nope", 1).Validate(people), ErrUnknownField)
+	require.ErrorIs(t, Or(Eq("name", "a"), Gt("nope", 1)).Validate(people), ErrUnknownField)
+	require.ErrorIs(t, In("age").Validate(people), ErrInvalidValue)
+	require.ErrorIs(t, And().Validate(people), ErrInvalidValue)
+	require.ErrorIs(t, Eq("age", make(chan int)).Validate(people), ErrInvalidValue)
+	require.ErrorIs(t, Contains("age", 1).Validate(people), ErrInvalidOp)
+	require.NoError(t, Contains("id", "6ba7").Validate(people))
+
+	// Values must be of the field type
+	require.ErrorIs(t, Eq("age", "old").Validate(people), ErrInvalidValue)
+	require.ErrorIs(t, In("age", 1, 2.5).Validate(people), ErrInvalidValue)
+	require.ErrorIs(t, Contains("name", 1).Validate(people), ErrInvalidValue)
+	require.ErrorIs(t, Gt("born", "yesterday").Validate(people), ErrInvalidValue)
+	require.NoError(t, Contains("tags", 1).Validate(people))
+}
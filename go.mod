@@ -1,11 +1,14 @@
 module github.com/instapi/client-go
 
-go 1.14
+go 1.18
 
 require (
 	github.com/davecgh/go-spew v1.1.1
+	github.com/klauspost/compress v1.17.0
 	github.com/stretchr/testify v1.7.0
 	github.com/tomnomnom/linkheader v0.0.0-20180905144013-02ca5825eb80
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
-	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
+
+require github.com/pmezard/go-difflib v1.0.0 // indirect
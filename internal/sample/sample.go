@@ -0,0 +1,413 @@
+package sample
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// Strategy represents a sampling strategy.
+type Strategy int
+
+// Sampling strategies.
+const (
+	Head Strategy = iota
+	Reservoir
+	Stratified
+)
+
+// Stratified sampling constants.
+const (
+	// strata is the number of sampled file segments.
+	strata = 16
+	// maxResync is the number of misaligned records skipped in a segment.
+	maxResync = 16
+)
+
+// Package errors.
+var (
+	ErrNotArray = errors.New("JSON input is neither an array nor newline delimited")
+)
+
+// Sampler samples well-formed records from an input up to a byte limit.
+type Sampler struct {
+	Strategy Strategy
+	Limit    int
+	Comma    rune
+	Rand     *rand.Rand
+}
+
+type row struct {
+	index int
+	b     []byte
+}
+
+// CSV samples the CSV records read from r, always including the header.
+// The stratified strategy requires r to implement io.ReadSeeker and falls
+// back to reservoir sampling otherwise.
+func (s *Sampler) CSV(r io.Reader) ([]byte, error) {
+	if s.Strategy == Stratified {
+		if rs, ok := r.(io.ReadSeeker); ok {
+			return s.stratifiedCSV(rs)
+		}
+	}
+
+	cr := s.csvReader(r)
+	header, err := cr.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := s.encodeCSV(header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	budget := s.Limit - len(h)
+	next := func() ([]byte, error) {
+		record, err := cr.Read()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return s.encodeCSV(record)
+	}
+
+	var rows []*row
+
+	if s.Strategy == Head {
+		rows, err = head(next, budget)
+	} else {
+		rows, err = s.reservoir(next, budget)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return join(h, rows, nil, nil), nil
+}
+
+// JSON samples the records of a JSON array or newline delimited JSON input,
+// returning a JSON array.
+func (s *Sampler) JSON(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+	b, err := firstByte(br)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+
+	switch b {
+	case '[':
+		_, err = dec.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+	case '{':
+
+	default:
+		return nil, ErrNotArray
+	}
+
+	next := func() ([]byte, error) {
+		if !dec.More() {
+			return nil, io.EOF
+		}
+
+		var v json.RawMessage
+		err := dec.Decode(&v)
+
+		return v, err
+	}
+
+	var rows []*row
+
+	// Account for the array brackets and element separators
+	budget := s.Limit - 2
+
+	if s.Strategy == Head {
+		rows, err = head(next, budget)
+	} else {
+		rows, err = s.reservoir(next, budget)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return join([]byte("["), rows, []byte(","), []byte("]")), nil
+}
+
+func (s *Sampler) csvReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	if s.Comma != 0 {
+		cr.Comma = s.Comma
+	}
+
+	return cr
+}
+
+func (s *Sampler) encodeCSV(record []string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+
+	if s.Comma != 0 {
+		w.Comma = s.Comma
+	}
+
+	err := w.Write(record)
+
+	if err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+// stratifiedCSV samples an equal share of the budget from evenly spaced
+// segments of the input.
+func (s *Sampler) stratifiedCSV(rs io.ReadSeeker) ([]byte, error) {
+	size, err := rs.Seek(0, io.SeekEnd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = rs.Seek(0, io.SeekStart)
+
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(rs)
+	cr := s.csvReader(br)
+	header, err := cr.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := s.encodeCSV(header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if size <= int64(s.Limit) {
+		_, err = rs.Seek(0, io.SeekStart)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return (&Sampler{Strategy: Head, Limit: s.Limit, Comma: s.Comma}).CSV(rs)
+	}
+
+	// Segments cannot overlap as the input is larger than the limit
+	var (
+		rows  []*row
+		share = (s.Limit - len(h)) / strata
+	)
+
+	for i := 0; i < strata; i++ {
+		_, err = rs.Seek(size*int64(i)/strata, io.SeekStart)
+
+		if err != nil {
+			return nil, err
+		}
+
+		br := bufio.NewReader(rs)
+
+		// Skip the header or the partial record at the segment start
+		_, err := br.ReadBytes('\n')
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		cr := s.csvReader(br)
+		cr.LazyQuotes = false
+		used := 0
+
+		for failures := 0; used < share && failures < maxResync; {
+			record, err := cr.Read()
+
+			if err == io.EOF {
+				break
+			}
+
+			// Records are misaligned when the segment starts within a quoted
+			// field, skip them until the reader is back in sync
+			if err != nil || len(record) != len(header) {
+				failures++
+				continue
+			}
+
+			b, err := s.encodeCSV(record)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if used+len(b) > share {
+				break
+			}
+
+			rows = append(rows, &row{index: len(rows), b: b})
+			used += len(b)
+		}
+	}
+
+	return join(h, rows, nil, nil), nil
+}
+
+func head(next func() ([]byte, error), budget int) ([]*row, error) {
+	var (
+		rows []*row
+		used int
+	)
+
+	for {
+		b, err := next()
+
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if used+len(b)+1 > budget {
+			return rows, nil
+		}
+
+		rows = append(rows, &row{index: len(rows), b: b})
+		used += len(b) + 1
+	}
+}
+
+// reservoir samples records uniformly across the whole input. The reservoir
+// holds as many records as the leading records fitting the budget and the
+// sample is trimmed to the budget once the input is exhausted.
+func (s *Sampler) reservoir(next func() ([]byte, error), budget int) ([]*row, error) {
+	rnd := s.Rand
+
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(rand.Int63())) // nolint: gosec
+	}
+
+	var (
+		rows []*row
+		used int
+		k    int
+	)
+
+	for i := 0; ; i++ {
+		b, err := next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Fill the budget before estimating the reservoir size
+		if k == 0 {
+			if used+len(b)+1 <= budget {
+				rows = append(rows, &row{index: i, b: b})
+				used += len(b) + 1
+
+				continue
+			}
+
+			k = len(rows)
+
+			if k == 0 {
+				return nil, nil
+			}
+		}
+
+		if j := rnd.Intn(i + 1); j < k {
+			rows[j] = &row{index: i, b: b}
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].index < rows[j].index })
+
+	// Replaced records may be larger than the ones they evicted
+	for size(rows) > budget && len(rows) > 0 {
+		j := rnd.Intn(len(rows))
+		rows = append(rows[:j], rows[j+1:]...)
+	}
+
+	return rows, nil
+}
+
+func size(rows []*row) int {
+	n := 0
+
+	for _, v := range rows {
+		n += len(v.b) + 1
+	}
+
+	return n
+}
+
+func join(prefix []byte, rows []*row, sep, suffix []byte) []byte {
+	buf := bytes.NewBuffer(append([]byte{}, prefix...))
+
+	for i, v := range rows {
+		if i > 0 {
+			buf.Write(sep)
+		}
+
+		buf.Write(v.b)
+	}
+
+	buf.Write(suffix)
+
+	return buf.Bytes()
+}
+
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+
+		if err != nil {
+			return 0, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return b, r.UnreadByte()
+	}
+}
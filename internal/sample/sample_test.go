@@ -0,0 +1,89 @@
+package sample
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCSV(n int) string {
+	var sb strings.Builder
+
+	sb.WriteString("id,name\n")
+
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "%d,\"name\n%d\"\n", i, i)
+	}
+
+	return sb.String()
+}
+
+func readCSV(t *testing.T, b []byte) [][]string {
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name"}, records[0])
+
+	return records[1:]
+}
+
+func TestHeadCSV(t *testing.T) {
+	s := &Sampler{Strategy: Head, Limit: 100}
+	b, err := s.CSV(strings.NewReader(testCSV(100)))
+
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(b), 100)
+
+	records := readCSV(t, b)
+
+	require.NotEmpty(t, records)
+	require.Equal(t, "0", records[0][0])
+}
+
+func TestReservoirCSV(t *testing.T) {
+	s := &Sampler{Strategy: Reservoir, Limit: 200, Rand: rand.New(rand.NewSource(1))}
+	b, err := s.CSV(strings.NewReader(testCSV(1000)))
+
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(b), 200)
+
+	records := readCSV(t, b)
+
+	require.NotEmpty(t, records)
+	require.NotEqual(t, "0", records[len(records)-1][0])
+}
+
+func TestStratifiedCSV(t *testing.T) {
+	s := &Sampler{Strategy: Stratified, Limit: 1000}
+	b, err := s.CSV(strings.NewReader(testCSV(10000)))
+
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(b), 1000)
+
+	records := readCSV(t, b)
+
+	require.NotEmpty(t, records)
+}
+
+func TestJSON(t *testing.T) {
+	for _, data := range []string{
+		`[{"id":1},{"id":2},{"id":3},{"id":4}]`,
+		"{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n{\"id\":4}\n",
+	} {
+		s := &Sampler{Strategy: Head, Limit: 30}
+		b, err := s.JSON(strings.NewReader(data))
+
+		require.NoError(t, err)
+
+		var v []map[string]int
+
+		require.NoError(t, json.Unmarshal(b, &v))
+		require.Equal(t, []map[string]int{{"id": 1}, {"id": 2}, {"id": 3}}, v)
+	}
+}
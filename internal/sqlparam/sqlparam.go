@@ -0,0 +1,445 @@
+// Package sqlparam parses SQL query placeholders and interpolates arguments
+// as safely escaped literals.
+package sqlparam
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// Package errors.
+var (
+	ErrArgCount           = errors.New("wrong number of query arguments")
+	ErrMissingArg         = errors.New("missing named query argument")
+	ErrMixedPlaceholders  = errors.New("mixed positional and named placeholders")
+	ErrUnsupportedArg     = errors.New("unsupported query argument type")
+	ErrInvalidArg         = errors.New("invalid query argument")
+	ErrUnterminated       = errors.New("unterminated quoted string or comment")
+	ErrInvalidPlaceholder = errors.New("invalid placeholder")
+)
+
+// Kind represents a placeholder kind.
+type Kind int
+
+// Placeholder kinds.
+const (
+	// Positional placeholders are ? and take arguments in order.
+	Positional Kind = iota
+	// Numbered placeholders are $1 or ?1 and refer to 1-based arguments.
+	Numbered
+	// Named placeholders are :name, @name or, in SQLite, $name.
+	Named
+)
+
+// Placeholder represents a placeholder found in a query.
+type Placeholder struct {
+	Kind  Kind
+	Start int
+	End   int
+	// Index is the 0-based argument index of positional and numbered
+	// placeholders.
+	Index int
+	// Name is the name of named placeholders.
+	Name string
+}
+
+// Parse returns the placeholders of the query, skipping quoted strings,
+// quoted identifiers and comments. Positional and numbered placeholders may be
+// mixed, but not with named placeholders. Note the ? placeholder conflicts
+// with the Postgres JSONB existence operators.
+func Parse(query string, dialect schema.Dialect) ([]*Placeholder, error) {
+	if dialect != schema.SQLite && dialect != schema.Postgres {
+		return nil, fmt.Errorf("%w: %s", schema.ErrUnsupportedDialect, dialect)
+	}
+
+	var (
+		placeholders []*Placeholder
+		positional   int
+	)
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == '\'':
+			escapes := dialect == schema.Postgres && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && !isIdent(query, i-2)
+			end, err := skipQuoted(query, i, '\'', escapes)
+
+			if err != nil {
+				return nil, err
+			}
+
+			i = end
+
+		case c == '"' || (c == '`' && dialect == schema.SQLite):
+			end, err := skipQuoted(query, i, c, false)
+
+			if err != nil {
+				return nil, err
+			}
+
+			i = end
+
+		case c == '[' && dialect == schema.SQLite:
+			end := strings.IndexByte(query[i:], ']')
+
+			if end < 0 {
+				return nil, fmt.Errorf("%w at offset %d", ErrUnterminated, i)
+			}
+
+			i += end + 1
+
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+
+			if end < 0 {
+				return placeholders, nil
+			}
+
+			i += end + 1
+
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+
+			if end < 0 {
+				return nil, fmt.Errorf("%w at offset %d", ErrUnterminated, i)
+			}
+
+			i += end + 4
+
+		case (c == '?' || c == '$') && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+
+			n, err := strconv.Atoi(query[i+1 : end])
+
+			if err != nil || n == 0 {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPlaceholder, query[i:end])
+			}
+
+			placeholders = append(placeholders, &Placeholder{Kind: Numbered, Start: i, End: end, Index: n - 1})
+			i = end
+
+		case c == '?':
+			placeholders = append(placeholders, &Placeholder{Kind: Positional, Start: i, End: i + 1, Index: positional})
+			positional++
+			i++
+
+		case c == '$' && dialect == schema.Postgres && !isIdent(query, i-1):
+			end, ok, err := skipDollarQuoted(query, i)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				end = i + 1
+			}
+
+			i = end
+
+		case c == ':' && strings.HasPrefix(query[i:], "::"):
+			// Postgres type cast
+			i += 2
+
+		case (c == ':' || c == '@' || c == '$') && !isIdent(query, i-1) && i+1 < len(query) && isIdentStart(query[i+1:]):
+			end := i + 1
+
+			for end < len(query) && isIdent(query, end) {
+				end++
+			}
+
+			placeholders = append(placeholders, &Placeholder{Kind: Named, Start: i, End: end, Name: query[i+1 : end]})
+			i = end
+
+		default:
+			i++
+		}
+	}
+
+	named := false
+
+	for i, v := range placeholders {
+		if i > 0 && (v.Kind == Named) != named {
+			return nil, ErrMixedPlaceholders
+		}
+
+		named = v.Kind == Named
+	}
+
+	return placeholders, nil
+}
+
+// Args returns the number of positional arguments and the names of the named
+// arguments the placeholders refer to.
+func Args(placeholders []*Placeholder) (int, []string) {
+	var (
+		n     int
+		names []string
+		seen  = map[string]bool{}
+	)
+
+	for _, v := range placeholders {
+		switch v.Kind {
+		case Named:
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				names = append(names, v.Name)
+			}
+
+		default:
+			if v.Index+1 > n {
+				n = v.Index + 1
+			}
+		}
+	}
+
+	return n, names
+}
+
+// Check verifies the arguments match the query placeholders.
+func Check(placeholders []*Placeholder, args []interface{}, named map[string]interface{}) error {
+	n, names := Args(placeholders)
+
+	if len(names) == 0 && n != len(args) {
+		return fmt.Errorf("%w: expected %d, got %d", ErrArgCount, n, len(args))
+	}
+
+	if len(names) > 0 && len(args) > 0 {
+		return ErrMixedPlaceholders
+	}
+
+	for _, v := range names {
+		if _, exists := named[v]; !exists {
+			return fmt.Errorf("%w: %s", ErrMissingArg, v)
+		}
+	}
+
+	return nil
+}
+
+// Interpolate replaces the query placeholders with the arguments formatted
+// as SQL literals of the given dialect.
+func Interpolate(query string, dialect schema.Dialect, args []interface{}, named map[string]interface{}) (string, error) {
+	placeholders, err := Parse(query, dialect)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = Check(placeholders, args, named)
+
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		sb   strings.Builder
+		last int
+	)
+
+	for _, v := range placeholders {
+		var arg interface{}
+
+		if v.Kind == Named {
+			arg = named[v.Name]
+		} else {
+			arg = args[v.Index]
+		}
+
+		literal, err := Literal(arg, dialect)
+
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", query[v.Start:v.End], err)
+		}
+
+		sb.WriteString(query[last:v.Start])
+		sb.WriteString(literal)
+		last = v.End
+	}
+
+	sb.WriteString(query[last:])
+
+	return sb.String(), nil
+}
+
+// Literal formats the value as a SQL literal of the given dialect.
+func Literal(v interface{}, dialect schema.Dialect) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		v, err = valuer.Value()
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+
+	case []byte:
+		if v == nil {
+			return "NULL", nil
+		}
+
+		if dialect == schema.Postgres {
+			return `E'\\x` + hex.EncodeToString(v) + `'::bytea`, nil
+		}
+
+		return "X'" + hex.EncodeToString(v) + "'", nil
+
+	case time.Time:
+		return quote(v.Format(time.RFC3339Nano), dialect)
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+
+		return Literal(rv.Elem().Interface(), dialect)
+
+	case reflect.Bool:
+		switch {
+		case dialect == schema.Postgres && rv.Bool():
+			return "TRUE", nil
+
+		case dialect == schema.Postgres:
+			return "FALSE", nil
+
+		case rv.Bool():
+			return "1", nil
+		}
+
+		return "0", nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("%w: %v", ErrInvalidArg, f)
+		}
+
+		return strconv.FormatFloat(f, 'g', -1, rv.Type().Bits()), nil
+
+	case reflect.String:
+		return quote(rv.String(), dialect)
+	}
+
+	return "", fmt.Errorf("%w: %T", ErrUnsupportedArg, v)
+}
+
+// quote quotes a string literal, doubling single quotes. Postgres strings
+// holding backslashes are written as escape strings, which are interpreted
+// the same regardless of the standard_conforming_strings setting.
+func quote(s string, dialect schema.Dialect) (string, error) {
+	if strings.IndexByte(s, 0) >= 0 {
+		return "", fmt.Errorf("%w: string contains NUL byte", ErrInvalidArg)
+	}
+
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("%w: string is not valid UTF-8", ErrInvalidArg)
+	}
+
+	s = strings.ReplaceAll(s, "'", "''")
+
+	if dialect == schema.Postgres && strings.Contains(s, `\`) {
+		return "E'" + strings.ReplaceAll(s, `\`, `\\`) + "'", nil
+	}
+
+	return "'" + s + "'", nil
+}
+
+// skipQuoted returns the offset following the quoted string starting at i,
+// where the quote is escaped by doubling it or, with escapes, a backslash.
+func skipQuoted(query string, i int, q byte, escapes bool) (int, error) {
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if escapes {
+				j++
+			}
+
+		case q:
+			if j+1 < len(query) && query[j+1] == q {
+				j++
+				continue
+			}
+
+			return j + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w at offset %d", ErrUnterminated, i)
+}
+
+// skipDollarQuoted skips a Postgres $tag$...$tag$ string starting at i,
+// reporting whether i starts one.
+func skipDollarQuoted(query string, i int) (int, bool, error) {
+	end := strings.IndexByte(query[i+1:], '$')
+
+	if end < 0 {
+		return 0, false, nil
+	}
+
+	tag := query[i : i+end+2]
+
+	for j := 1; j < len(tag)-1; j++ {
+		if !isIdent(tag, j) || (j == 1 && isDigit(tag[j])) {
+			return 0, false, nil
+		}
+	}
+
+	closing := strings.Index(query[i+len(tag):], tag)
+
+	if closing < 0 {
+		return 0, false, fmt.Errorf("%w at offset %d", ErrUnterminated, i)
+	}
+
+	return i + len(tag) + closing + len(tag), true, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isIdent reports whether the byte at i is part of an identifier.
+func isIdent(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+
+	c := s[i]
+
+	return c == '_' || c >= utf8.RuneSelf || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
@@ -0,0 +1,175 @@
+package sqlparam
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/instapi/client-go/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  schema.Dialect
+		query    string
+		args     []interface{}
+		named    map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "positional",
+			dialect:  schema.SQLite,
+			query:    "SELECT * FROM t WHERE a = ? AND b = ?",
+			args:     []interface{}{1, "x"},
+			expected: "SELECT * FROM t WHERE a = 1 AND b = 'x'",
+		},
+		{
+			name:     "numbered",
+			dialect:  schema.Postgres,
+			query:    "SELECT $2, $1, $2",
+			args:     []interface{}{"a", 2.5},
+			expected: "SELECT 2.5, 'a', 2.5",
+		},
+		{
+			name:     "sqlite numbered",
+			dialect:  schema.SQLite,
+			query:    "SELECT ?2, ?1",
+			args:     []interface{}{true, false},
+			expected: "SELECT 0, 1",
+		},
+		{
+			name:     "named",
+			dialect:  schema.SQLite,
+			query:    "SELECT :a, @b, $c, :a",
+			named:    map[string]interface{}{"a": nil, "b": int8(-3), "c": uint(7)},
+			expected: "SELECT NULL, -3, 7, NULL",
+		},
+		{
+			name:     "single quote injection",
+			dialect:  schema.SQLite,
+			query:    "SELECT * FROM users WHERE name = ?",
+			args:     []interface{}{"x' OR '1'='1"},
+			expected: "SELECT * FROM users WHERE name = 'x'' OR ''1''=''1'",
+		},
+		{
+			name:     "comment injection",
+			dialect:  schema.SQLite,
+			query:    "SELECT ? FROM t",
+			args:     []interface{}{"'; DROP TABLE t; --"},
+			expected: "SELECT '''; DROP TABLE t; --' FROM t",
+		},
+		{
+			name:     "postgres backslash",
+			dialect:  schema.Postgres,
+			query:    "SELECT ?",
+			args:     []interface{}{`a\' OR 1=1 --`},
+			expected: `SELECT E'a\\'' OR 1=1 --'`,
+		},
+		{
+			name:     "sqlite backslash",
+			dialect:  schema.SQLite,
+			query:    "SELECT ?",
+			args:     []interface{}{`a\'`},
+			expected: `SELECT 'a\'''`,
+		},
+		{
+			name:     "placeholders in literals and comments",
+			dialect:  schema.SQLite,
+			query:    "SELECT '?', \"?\", `:a`, [@b], 'it''s ?' -- ? :c\n, ? /* ? */",
+			args:     []interface{}{1},
+			expected: "SELECT '?', \"?\", `:a`, [@b], 'it''s ?' -- ? :c\n, 1 /* ? */",
+		},
+		{
+			name:     "postgres casts and dollar quotes",
+			dialect:  schema.Postgres,
+			query:    "SELECT $1::text, $$ :a ? $$, $tag$ $1 $tag$, E'\\' ?', '12:30'",
+			args:     []interface{}{"x"},
+			expected: "SELECT 'x'::text, $$ :a ? $$, $tag$ $1 $tag$, E'\\' ?', '12:30'",
+		},
+		{
+			name:     "quoted identifier with escaped quote",
+			dialect:  schema.Postgres,
+			query:    `SELECT "a""?" FROM t WHERE x = ?`,
+			args:     []interface{}{[]byte{0xde, 0xad}},
+			expected: `SELECT "a""?" FROM t WHERE x = E'\\xdead'::bytea`,
+		},
+		{
+			name:     "bytes and time",
+			dialect:  schema.SQLite,
+			query:    "SELECT ?, ?",
+			args:     []interface{}{[]byte("hi"), time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)},
+			expected: "SELECT X'6869', '2021-01-02T03:04:05Z'",
+		},
+		{
+			name:     "valuer and pointers",
+			dialect:  schema.SQLite,
+			query:    "SELECT ?, ?, ?",
+			args:     []interface{}{sql.NullString{String: "a", Valid: true}, sql.NullInt64{}, (*int)(nil)},
+			expected: "SELECT 'a', NULL, NULL",
+		},
+		{
+			name:     "unicode",
+			dialect:  schema.SQLite,
+			query:    "SELECT :ñame",
+			named:    map[string]interface{}{"ñame": "日本'語"},
+			expected: "SELECT '日本''語'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Interpolate(tt.query, tt.dialect, tt.args, tt.named)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, s)
+		})
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		named map[string]interface{}
+		err   error
+	}{
+		{"too few", "SELECT ?, ?", []interface{}{1}, nil, ErrArgCount},
+		{"too many", "SELECT ?", []interface{}{1, 2}, nil, ErrArgCount},
+		{"missing named", "SELECT :a, :b", nil, map[string]interface{}{"a": 1}, ErrMissingArg},
+		{"mixed", "SELECT ?, :a", []interface{}{1}, nil, ErrMixedPlaceholders},
+		{"unterminated string", "SELECT 'abc", nil, nil, ErrUnterminated},
+		{"unterminated comment", "SELECT /* ?", nil, nil, ErrUnterminated},
+		{"zero placeholder", "SELECT $0", []interface{}{1}, nil, ErrInvalidPlaceholder},
+		{"NUL byte", "SELECT ?", []interface{}{"a\x00b"}, nil, ErrInvalidArg},
+		{"invalid UTF-8", "SELECT ?", []interface{}{"\xff"}, nil, ErrInvalidArg},
+		{"NaN", "SELECT ?", []interface{}{zero / zero}, nil, ErrInvalidArg},
+		{"unsupported", "SELECT ?", []interface{}{[]int{1}}, nil, ErrUnsupportedArg},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Interpolate(tt.query, schema.SQLite, tt.args, tt.named)
+
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err := Interpolate("SELECT 1", "mysql", nil, nil)
+	require.ErrorIs(t, err, schema.ErrUnsupportedDialect)
+}
+
+var zero float64
+
+func TestArgs(t *testing.T) {
+	p, err := Parse("SELECT :a, :b, :a", schema.SQLite)
+
+	require.NoError(t, err)
+
+	n, names := Args(p)
+
+	require.Zero(t, n)
+	require.Equal(t, []string{"a", "b"}, names)
+}
@@ -0,0 +1,213 @@
+// Package xlsx writes single sheet XLSX workbooks, streaming rows as they are
+// written.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// MaxRows is the maximum number of rows of a sheet.
+const MaxRows = 1048576
+
+// Package errors.
+var (
+	ErrTooManyRows = errors.New("sheet row limit exceeded")
+)
+
+const (
+	contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+
+	rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	workbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+
+	workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+
+	sheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	sheetEnd = `</sheetData></worksheet>`
+)
+
+// Writer writes the rows of a single sheet workbook. Strings are written
+// inline, so rows are not held in memory.
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// NewWriter writes the workbook parts preceding the sheet to w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	zw := zip.NewWriter(w)
+
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+	} {
+		pw, err := zw.Create(part.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = io.WriteString(pw, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	pw, err := zw.Create("xl/worksheets/sheet1.xml")
+
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := bufio.NewWriter(pw)
+
+	if _, err = sheet.WriteString(sheetStart); err != nil {
+		return nil, err
+	}
+
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow writes a row. Numbers and booleans are written as such, nil
+// values as empty cells, times as RFC 3339 strings and other values as text,
+// JSON encoding maps and slices.
+func (w *Writer) WriteRow(values []interface{}) error {
+	if w.rows == MaxRows {
+		return ErrTooManyRows
+	}
+
+	w.rows++
+	row := strconv.Itoa(w.rows)
+
+	// Write errors are sticky and returned by the final write
+	w.sheet.WriteString(`<row r="` + row + `">`) // nolint: errcheck
+
+	for i, v := range values {
+		ref := Column(i) + row
+
+		switch typ, value := cell(v); typ {
+		case "":
+
+		case "n", "b":
+			w.sheet.WriteString(`<c r="` + ref + `" t="` + typ + `"><v>` + value + `</v></c>`) // nolint: errcheck
+
+		default:
+			w.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`) // nolint: errcheck
+			xml.EscapeText(w.sheet, []byte(value))                                               // nolint: errcheck
+			w.sheet.WriteString(`</t></is></c>`)                                                 // nolint: errcheck
+		}
+	}
+
+	_, err := w.sheet.WriteString(`</row>`)
+
+	return err
+}
+
+// Close completes the workbook. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if _, err := w.sheet.WriteString(sheetEnd); err != nil {
+		return err
+	}
+
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return w.zw.Close()
+}
+
+// cell returns the cell type and value, with an empty type for nil values.
+func cell(v interface{}) (string, string) {
+	switch v := v.(type) {
+	case nil:
+		return "", ""
+
+	case string:
+		return "s", v
+
+	case json.Number:
+		f, err := v.Float64()
+
+		if err != nil || math.IsInf(f, 0) {
+			return "s", v.String()
+		}
+
+		return "n", v.String()
+
+	case bool:
+		if v {
+			return "b", "1"
+		}
+
+		return "b", "0"
+
+	case time.Time:
+		return "s", v.Format(time.RFC3339Nano)
+
+	case []byte:
+		return "s", string(v)
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "n", strconv.FormatInt(rv.Int(), 10)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "n", strconv.FormatUint(rv.Uint(), 10)
+
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", ""
+		}
+
+		return "n", strconv.FormatFloat(f, 'g', -1, rv.Type().Bits())
+	}
+
+	b, _ := json.Marshal(v)
+
+	return "s", string(b)
+}
+
+// Column returns the letters of the 0-based column index, e.g. AA for 26.
+func Column(i int) string {
+	var b []byte
+
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,57 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow([]interface{}{"name", "age", "active", "tags"}))
+	require.NoError(t, w.WriteRow([]interface{}{"<a & b>", json.Number("42"), true, []interface{}{"x"}}))
+	require.NoError(t, w.WriteRow([]interface{}{nil, 1.5}))
+	require.NoError(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var names []string
+
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+
+	require.Equal(t, []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/worksheets/sheet1.xml",
+	}, names)
+
+	r, err := zr.File[4].Open()
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `<c r="A2" t="inlineStr"><is><t xml:space="preserve">&lt;a &amp; b&gt;</t></is></c>`+
+		`<c r="B2" t="n"><v>42</v></c><c r="C2" t="b"><v>1</v></c>`+
+		`<c r="D2" t="inlineStr"><is><t xml:space="preserve">[&#34;x&#34;]</t></is></c></row>`+
+		`<row r="3"><c r="B3" t="n"><v>1.5</v></c></row></sheetData></worksheet>`)
+}
+
+func TestColumn(t *testing.T) {
+	require.Equal(t, "A", Column(0))
+	require.Equal(t, "Z", Column(25))
+	require.Equal(t, "AA", Column(26))
+	require.Equal(t, "AZ", Column(51))
+	require.Equal(t, "XFD", Column(16383))
+}
@@ -0,0 +1,287 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Package errors.
+var (
+	ErrNotObject = errors.New("merge patch target is not an object")
+	ErrNullValue = errors.New("merge patch cannot set a null value")
+)
+
+// Operation types as defined by RFC 6902.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation represents a RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, always including the
+// value member for operations that require one, even when it is null.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	switch o.Op {
+	case OpAdd, OpReplace, OpTest:
+		return json.Marshal(struct {
+			Op    string      `json:"op"`
+			Path  string      `json:"path"`
+			Value interface{} `json:"value"`
+		}{o.Op, o.Path, o.Value})
+
+	case OpMove, OpCopy:
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			From string `json:"from"`
+			Path string `json:"path"`
+		}{o.Op, o.From, o.Path})
+	}
+
+	return json.Marshal(struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+	}{o.Op, o.Path})
+}
+
+// Patch represents a RFC 6902 JSON Patch document.
+type Patch []Operation
+
+// New creates a new empty patch.
+func New() Patch {
+	return Patch{}
+}
+
+// Add appends an add operation.
+func (p Patch) Add(path string, value interface{}) Patch {
+	return p.append(Operation{Op: OpAdd, Path: path, Value: value})
+}
+
+// Remove appends a remove operation.
+func (p Patch) Remove(path string) Patch {
+	return p.append(Operation{Op: OpRemove, Path: path})
+}
+
+// Replace appends a replace operation.
+func (p Patch) Replace(path string, value interface{}) Patch {
+	return p.append(Operation{Op: OpReplace, Path: path, Value: value})
+}
+
+// Move appends a move operation.
+func (p Patch) Move(from, path string) Patch {
+	return p.append(Operation{Op: OpMove, From: from, Path: path})
+}
+
+// Copy appends a copy operation.
+func (p Patch) Copy(from, path string) Patch {
+	return p.append(Operation{Op: OpCopy, From: from, Path: path})
+}
+
+// Test appends a test operation.
+func (p Patch) Test(path string, value interface{}) Patch {
+	return p.append(Operation{Op: OpTest, Path: path, Value: value})
+}
+
+// append returns a copy of the patch with the operation appended, so that
+// patches built from a common base do not share operations.
+func (p Patch) append(o Operation) Patch {
+	return append(p[:len(p):len(p)], o)
+}
+
+// Merge represents a RFC 7396 JSON Merge Patch document.
+type Merge map[string]interface{}
+
+// Pointer builds a RFC 6901 JSON Pointer from the given reference tokens.
+func Pointer(tokens ...string) string {
+	var sb strings.Builder
+
+	for _, v := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escaper.Replace(v))
+	}
+
+	return sb.String()
+}
+
+var escaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// Diff computes a minimal JSON Patch transforming from into to. Both values
+// are compared by their JSON representation.
+func Diff(from, to interface{}) (Patch, error) {
+	a, b, err := normalize(from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return diff(New(), "", a, b), nil
+}
+
+func diff(p Patch, path string, a, b interface{}) Patch {
+	if reflect.DeepEqual(a, b) {
+		return p
+	}
+
+	x, ok := a.(map[string]interface{})
+	y, ok2 := b.(map[string]interface{})
+
+	if !ok || !ok2 {
+		return p.Replace(path, b)
+	}
+
+	for _, k := range sortedKeys(x) {
+		if _, exists := y[k]; !exists {
+			p = p.Remove(path + Pointer(k))
+		}
+	}
+
+	for _, k := range sortedKeys(y) {
+		v, exists := x[k]
+
+		if !exists {
+			p = p.Add(path+Pointer(k), y[k])
+			continue
+		}
+
+		p = diff(p, path+Pointer(k), v, y[k])
+	}
+
+	return p
+}
+
+// MergeDiff computes a JSON Merge Patch transforming from into to. Both
+// values must encode to JSON objects. A null member of to, unless unchanged,
+// is rejected with ErrNullValue, as null removes members in a merge patch.
+func MergeDiff(from, to interface{}) (Merge, error) {
+	a, b, err := normalize(from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	x, _ := a.(map[string]interface{})
+	y, ok := b.(map[string]interface{})
+
+	if !ok {
+		return nil, ErrNotObject
+	}
+
+	return mergeDiff(x, y)
+}
+
+func mergeDiff(a, b map[string]interface{}) (Merge, error) {
+	m := Merge{}
+
+	for k := range a {
+		if _, exists := b[k]; !exists {
+			m[k] = nil
+		}
+	}
+
+	for k, v := range b {
+		old, exists := a[k]
+
+		if exists && reflect.DeepEqual(old, v) {
+			continue
+		}
+
+		x, ok := old.(map[string]interface{})
+		y, ok2 := v.(map[string]interface{})
+
+		if exists && ok && ok2 {
+			d, err := mergeDiff(x, y)
+
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = d
+
+			continue
+		}
+
+		if hasNull(v) {
+			return nil, fmt.Errorf("%w: %s", ErrNullValue, k)
+		}
+
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+// hasNull reports whether v is null or an object holding a null member.
+func hasNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	m, ok := v.(map[string]interface{})
+
+	if !ok {
+		return false
+	}
+
+	for _, v := range m {
+		if hasNull(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalize(from, to interface{}) (a, b interface{}, err error) {
+	a, err = roundTrip(from)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b, err = roundTrip(to)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return a, b, nil
+}
+
+func roundTrip(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var dst interface{}
+	err = json.Unmarshal(b, &dst)
+
+	return dst, err
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
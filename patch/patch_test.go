@@ -0,0 +1,99 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	p := New().
+		Test("/name", "acme").
+		Replace("/name", "Acme").
+		Add("/tags/-", nil).
+		Remove("/legacy")
+
+	b, err := json.Marshal(p)
+
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"op":"test","path":"/name","value":"acme"},
+		{"op":"replace","path":"/name","value":"Acme"},
+		{"op":"add","path":"/tags/-","value":null},
+		{"op":"remove","path":"/legacy"}
+	]`, string(b))
+}
+
+func TestBuilderCopies(t *testing.T) {
+	base := make(Patch, 0, 4).Test("/version", 1)
+	a := base.Replace("/name", "a")
+	b := base.Replace("/name", "b")
+
+	require.Len(t, base, 1)
+	require.Equal(t, "a", a[1].Value)
+	require.Equal(t, "b", b[1].Value)
+}
+
+func TestPointer(t *testing.T) {
+	require.Equal(t, "/a~1b/m~0n", Pointer("a/b", "m~n"))
+	require.Equal(t, "", Pointer())
+}
+
+func TestDiff(t *testing.T) {
+	from := map[string]interface{}{
+		"name":    "acme",
+		"legacy":  true,
+		"address": map[string]interface{}{"city": "Leeds", "zip": "LS1"},
+		"tags":    []string{"a"},
+	}
+	to := map[string]interface{}{
+		"name":    "Acme",
+		"address": map[string]interface{}{"city": "Leeds", "country": "UK"},
+		"tags":    []string{"a", "b"},
+	}
+
+	p, err := Diff(from, to)
+
+	require.NoError(t, err)
+	require.Equal(t, Patch{
+		{Op: OpRemove, Path: "/legacy"},
+		{Op: OpRemove, Path: "/address/zip"},
+		{Op: OpAdd, Path: "/address/country", Value: "UK"},
+		{Op: OpReplace, Path: "/name", Value: "Acme"},
+		{Op: OpReplace, Path: "/tags", Value: []interface{}{"a", "b"}},
+	}, p)
+
+	p, err = Diff(to, to)
+
+	require.NoError(t, err)
+	require.Empty(t, p)
+}
+
+func TestMergeDiff(t *testing.T) {
+	m, err := MergeDiff(
+		map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 1, "d": 2}, "e": "x"},
+		map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 2}},
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, Merge{"b": Merge{"c": 2.0}, "e": nil}, m)
+
+	_, err = MergeDiff(nil, []int{1})
+
+	require.ErrorIs(t, err, ErrNotObject)
+
+	// Null members cannot be set, only unchanged
+	_, err = MergeDiff(map[string]interface{}{"a": 1}, map[string]interface{}{"a": nil})
+
+	require.ErrorIs(t, err, ErrNullValue)
+
+	_, err = MergeDiff(map[string]interface{}{}, map[string]interface{}{"b": map[string]interface{}{"c": nil}})
+
+	require.ErrorIs(t, err, ErrNullValue)
+
+	m, err = MergeDiff(map[string]interface{}{"a": nil, "b": 1}, map[string]interface{}{"a": nil, "b": []interface{}{nil}})
+
+	require.NoError(t, err)
+	require.Equal(t, Merge{"b": []interface{}{nil}}, m)
+}
@@ -2,23 +2,147 @@ package instapi
 
 import (
 	"context"
+	"database/sql/driver"
 	"net/http"
 	"strings"
 
+	"github.com/instapi/client-go/internal/sqlparam"
 	"github.com/instapi/client-go/types"
 )
 
-// Query performs a SQL query.
-func (c *Client) Query(ctx context.Context, query string, dst interface{}) error {
-	_, _, err := c.doRequest(
-		ctx,
-		http.MethodPost,
-		types.SQL,
-		c.endpoint+"query",
-		http.StatusOK,
-		strings.NewReader(query),
-		dst,
-	)
-
-	return err
+// queryRequest represents a query with bound parameters.
+type queryRequest struct {
+	Query     string                 `json:"query"`
+	Args      []interface{}          `json:"args,omitempty"`
+	NamedArgs map[string]interface{} `json:"namedArgs,omitempty"`
+}
+
+// queryFunc sends a query request body of the given content type.
+type queryFunc func(contentType string, src interface{}) error
+
+// Query performs a SQL query. The arguments are bound to the positional ? or
+// numbered $1 placeholders of the query. Should the API not accept bound
+// parameters, they are escaped client side per the SQLDialect of the client.
+func (c *Client) Query(ctx context.Context, query string, dst interface{}, args []interface{}, options ...RequestOption) error {
+	dst = queryDst(dst, options)
+
+	return c.query(query, args, nil, func(contentType string, src interface{}) error {
+		_, _, err := c.doRequest(ctx, http.MethodPost, contentType, c.endpoint+"query", http.StatusOK, src, dst, options...)
+		return err
+	})
+}
+
+// QueryNamed performs a SQL query with the arguments bound to the named :name
+// or @name placeholders of the query.
+func (c *Client) QueryNamed(ctx context.Context, query string, dst interface{}, args map[string]interface{}, options ...RequestOption) error {
+	dst = queryDst(dst, options)
+
+	return c.query(query, nil, args, func(contentType string, src interface{}) error {
+		_, _, err := c.doRequest(ctx, http.MethodPost, contentType, c.endpoint+"query", http.StatusOK, src, dst, options...)
+		return err
+	})
+}
+
+func (c *Client) query(query string, args []interface{}, named map[string]interface{}, fn queryFunc) error {
+	if len(args) == 0 && len(named) == 0 {
+		return fn(types.SQL, strings.NewReader(query))
+	}
+
+	placeholders, err := sqlparam.Parse(query, c.dialect)
+
+	if err != nil {
+		return err
+	}
+
+	err = sqlparam.Check(placeholders, args, named)
+
+	if err != nil {
+		return err
+	}
+
+	if !c.queryUnbound() {
+		req, err := newQueryRequest(query, args, named)
+
+		if err != nil {
+			return err
+		}
+
+		err = fn(types.JSON, req)
+
+		if !isUnsupportedMediaType(err) {
+			return err
+		}
+
+		c.mu.Lock()
+		c.unbound = true
+		c.mu.Unlock()
+	}
+
+	query, err = sqlparam.Interpolate(query, c.dialect, args, named)
+
+	if err != nil {
+		return err
+	}
+
+	return fn(types.SQL, strings.NewReader(query))
+}
+
+func newQueryRequest(query string, args []interface{}, named map[string]interface{}) (*queryRequest, error) {
+	req := &queryRequest{Query: query}
+
+	if len(args) > 0 {
+		req.Args = make([]interface{}, len(args))
+
+		for i, v := range args {
+			v, err := argValue(v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			req.Args[i] = v
+		}
+	}
+
+	if len(named) > 0 {
+		req.NamedArgs = make(map[string]interface{}, len(named))
+
+		for k, v := range named {
+			v, err := argValue(v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			req.NamedArgs[k] = v
+		}
+	}
+
+	return req, nil
+}
+
+// queryUnbound reports whether the API rejected bound query parameters.
+func (c *Client) queryUnbound() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.unbound
+}
+
+// queryDst returns the query destination, which is nil for dry runs as
+// they have no results.
+func queryDst(dst interface{}, options []RequestOption) interface{} {
+	if _, exists := findOption(options, "dryRun"); exists {
+		return nil
+	}
+
+	return dst
+}
+
+func argValue(v interface{}) (interface{}, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	return v, nil
 }
@@ -0,0 +1,209 @@
+package query
+
+// Condition represents a WHERE clause condition.
+type Condition interface {
+	build(w *writer) error
+}
+
+type comparison struct {
+	field string
+	op    string
+	value interface{}
+}
+
+func (c *comparison) build(w *writer) error {
+	if err := w.field(c.field); err != nil {
+		return err
+	}
+
+	w.WriteString(" " + c.op + " ")
+	w.arg(c.value)
+
+	return nil
+}
+
+// Eq matches a field equal to the value. A nil value matches NULL.
+func Eq(field string, v interface{}) Condition {
+	if v == nil {
+		return IsNull(field)
+	}
+
+	return &comparison{field, "=", v}
+}
+
+// Ne matches a field not equal to the value. A nil value matches non NULL.
+func Ne(field string, v interface{}) Condition {
+	if v == nil {
+		return NotNull(field)
+	}
+
+	return &comparison{field, "<>", v}
+}
+
+// Lt matches a field less than the value.
+func Lt(field string, v interface{}) Condition {
+	return &comparison{field, "<", v}
+}
+
+// Le matches a field less than or equal to the value.
+func Le(field string, v interface{}) Condition {
+	return &comparison{field, "<=", v}
+}
+
+// Gt matches a field greater than the value.
+func Gt(field string, v interface{}) Condition {
+	return &comparison{field, ">", v}
+}
+
+// Ge matches a field greater than or equal to the value.
+func Ge(field string, v interface{}) Condition {
+	return &comparison{field, ">=", v}
+}
+
+// Like matches a field against a LIKE pattern.
+func Like(field, pattern string) Condition {
+	return &comparison{field, "LIKE", pattern}
+}
+
+type in struct {
+	field  string
+	values []interface{}
+}
+
+func (c *in) build(w *writer) error {
+	// An empty IN list is invalid SQL and matches nothing
+	if len(c.values) == 0 {
+		if err := w.check(c.field); err != nil {
+			return err
+		}
+
+		w.WriteString("1 = 0")
+
+		return nil
+	}
+
+	if err := w.field(c.field); err != nil {
+		return err
+	}
+
+	w.WriteString(" IN (")
+
+	for i, v := range c.values {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+
+		w.arg(v)
+	}
+
+	w.WriteString(")")
+
+	return nil
+}
+
+// In matches a field equal to any of the values.
+func In(field string, values ...interface{}) Condition {
+	return &in{field, values}
+}
+
+type null struct {
+	field string
+	not   bool
+}
+
+func (c *null) build(w *writer) error {
+	if err := w.field(c.field); err != nil {
+		return err
+	}
+
+	if c.not {
+		w.WriteString(" IS NOT NULL")
+	} else {
+		w.WriteString(" IS NULL")
+	}
+
+	return nil
+}
+
+// IsNull matches a NULL field.
+func IsNull(field string) Condition {
+	return &null{field: field}
+}
+
+// NotNull matches a non NULL field.
+func NotNull(field string) Condition {
+	return &null{field: field, not: true}
+}
+
+type logical struct {
+	op         string
+	conditions []Condition
+}
+
+func (c *logical) build(w *writer) error {
+	switch {
+	case len(c.conditions) == 0 && c.op == "AND":
+		w.WriteString("1 = 1")
+		return nil
+
+	case len(c.conditions) == 0:
+		w.WriteString("1 = 0")
+		return nil
+
+	case len(c.conditions) == 1:
+		return c.conditions[0].build(w)
+	}
+
+	for i, v := range c.conditions {
+		if i > 0 {
+			w.WriteString(" " + c.op + " ")
+		}
+
+		_, nested := v.(*logical)
+
+		if nested {
+			w.WriteString("(")
+		}
+
+		if err := v.build(w); err != nil {
+			return err
+		}
+
+		if nested {
+			w.WriteString(")")
+		}
+	}
+
+	return nil
+}
+
+// And matches when all the conditions hold.
+func And(conditions ...Condition) Condition {
+	return &logical{"AND", conditions}
+}
+
+// Or matches when any of the conditions holds.
+func Or(conditions ...Condition) Condition {
+	return &logical{"OR", conditions}
+}
+
+type not struct {
+	condition Condition
+}
+
+func (c *not) build(w *writer) error {
+	w.WriteString("NOT (")
+
+	if err := c.condition.build(w); err != nil {
+		return err
+	}
+
+	w.WriteString(")")
+
+	return nil
+}
+
+// Not negates the condition.
+func Not(condition Condition) Condition {
+	return &not{condition}
+}
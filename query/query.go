@@ -0,0 +1,224 @@
+// Package query builds parameterized SQL queries against schemas:
+//
+//	q, args, err := query.Select("name", "age").
+//		From("acme", s).
+//		Where(query.Gt("age", 18), query.In("country", "NZ", "AU")).
+//		OrderBy(query.Desc("age")).
+//		Limit(10).
+//		Build()
+//
+//	err = client.Query(ctx, q, &dst, args)
+//
+// Identifiers are quoted and values are bound as arguments, never rendered
+// into the query. Field names are validated against the schema.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// Builder errors.
+var (
+	ErrNoSource     = errors.New("query has no source schema")
+	ErrUnknownField = errors.New("unknown field")
+	ErrInvalidLimit = errors.New("invalid limit or offset")
+)
+
+// Builder represents a SELECT query.
+type Builder struct {
+	fields     []string
+	account    string
+	schema     *schema.Schema
+	conditions []Condition
+	orders     []Order
+	limit      int
+	offset     int
+	dialect    schema.Dialect
+}
+
+// Select returns a query selecting the given fields, or all fields if none.
+func Select(fields ...string) *Builder {
+	return &Builder{fields: fields, dialect: schema.SQLite}
+}
+
+// From sets the account and schema queried. Field names are validated
+// against the schema.
+func (b *Builder) From(account string, s *schema.Schema) *Builder {
+	b.account = account
+	b.schema = s
+
+	return b
+}
+
+// Where adds conditions, which must all hold.
+func (b *Builder) Where(conditions ...Condition) *Builder {
+	b.conditions = append(b.conditions, conditions...)
+	return b
+}
+
+// OrderBy adds sort orders.
+func (b *Builder) OrderBy(orders ...Order) *Builder {
+	b.orders = append(b.orders, orders...)
+	return b
+}
+
+// Limit sets the maximum number of results.
+func (b *Builder) Limit(n int) *Builder {
+	b.limit = n
+	return b
+}
+
+// Offset sets the number of results skipped.
+func (b *Builder) Offset(n int) *Builder {
+	b.offset = n
+	return b
+}
+
+// Dialect sets the SQL dialect, determining the placeholder syntax. The
+// default is SQLite, matching the client default.
+func (b *Builder) Dialect(d schema.Dialect) *Builder {
+	b.dialect = d
+	return b
+}
+
+// Build renders the query and its arguments.
+func (b *Builder) Build() (string, []interface{}, error) {
+	if b.schema == nil {
+		return "", nil, ErrNoSource
+	}
+
+	if b.dialect != schema.SQLite && b.dialect != schema.Postgres {
+		return "", nil, fmt.Errorf("%w: %s", schema.ErrUnsupportedDialect, b.dialect)
+	}
+
+	if b.limit < 0 || b.offset < 0 {
+		return "", nil, ErrInvalidLimit
+	}
+
+	w := &writer{schema: b.schema, dialect: b.dialect}
+	w.WriteString("SELECT ")
+
+	if len(b.fields) == 0 {
+		w.WriteString("*")
+	}
+
+	for i, v := range b.fields {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+
+		if err := w.field(v); err != nil {
+			return "", nil, err
+		}
+	}
+
+	w.WriteString(" FROM ")
+
+	if b.account != "" {
+		w.WriteString(schema.QuoteIdentifier(b.account))
+		w.WriteString(".")
+	}
+
+	w.WriteString(schema.QuoteIdentifier(b.schema.Name))
+
+	if len(b.conditions) > 0 {
+		w.WriteString(" WHERE ")
+
+		if err := And(b.conditions...).build(w); err != nil {
+			return "", nil, err
+		}
+	}
+
+	for i, v := range b.orders {
+		if i == 0 {
+			w.WriteString(" ORDER BY ")
+		} else {
+			w.WriteString(", ")
+		}
+
+		if err := w.field(v.Field); err != nil {
+			return "", nil, err
+		}
+
+		if v.Desc {
+			w.WriteString(" DESC")
+		}
+	}
+
+	if b.limit > 0 {
+		w.WriteString(" LIMIT ")
+		w.WriteString(strconv.Itoa(b.limit))
+	}
+
+	if b.offset > 0 {
+		// SQLite requires a LIMIT clause for OFFSET
+		if b.limit == 0 && b.dialect == schema.SQLite {
+			w.WriteString(" LIMIT -1")
+		}
+
+		w.WriteString(" OFFSET ")
+		w.WriteString(strconv.Itoa(b.offset))
+	}
+
+	return w.String(), w.args, nil
+}
+
+// Order represents a sort order.
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Asc sorts by the field in ascending order.
+func Asc(field string) Order {
+	return Order{Field: field}
+}
+
+// Desc sorts by the field in descending order.
+func Desc(field string) Order {
+	return Order{Field: field, Desc: true}
+}
+
+// writer renders a query, collecting its arguments.
+type writer struct {
+	strings.Builder
+	schema  *schema.Schema
+	dialect schema.Dialect
+	args    []interface{}
+}
+
+// field writes a quoted field name, validating it against the schema.
+func (w *writer) field(name string) error {
+	if err := w.check(name); err != nil {
+		return err
+	}
+
+	w.WriteString(schema.QuoteIdentifier(name))
+
+	return nil
+}
+
+// check validates the field name against the schema.
+func (w *writer) check(name string) error {
+	if w.schema.Field(name) == nil {
+		return fmt.Errorf("%w: %s.%s", ErrUnknownField, w.schema.Name, name)
+	}
+
+	return nil
+}
+
+// arg writes a placeholder bound to the value.
+func (w *writer) arg(v interface{}) {
+	w.args = append(w.args, v)
+
+	if w.dialect == schema.Postgres {
+		w.WriteString("$" + strconv.Itoa(len(w.args)))
+	} else {
+		w.WriteString("?")
+	}
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/instapi/client-go/schema"
+	"github.com/stretchr/testify/require"
+)
+
+var people = &schema.Schema{
+	Name: "people",
+	Fields: []*schema.Field{
+		{Name: "name", Type: schema.String},
+		{Name: "age", Type: schema.Integer},
+		{Name: "country", Type: schema.String},
+		{Name: `odd"name`, Type: schema.String},
+	},
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  *Builder
+		expected string
+		args     []interface{}
+	}{
+		{
+			name:     "all fields",
+			builder:  Select().From("", people),
+			expected: `SELECT * FROM "people"`,
+		},
+		{
+			name: "conditions",
+			builder: Select("name", `odd"name`).
+				From("acme", people).
+				Where(Gt("age", 18), Or(Eq("country", "NZ"), In("country", "AU", "FJ")), Eq("name", nil)).
+				OrderBy(Desc("age"), Asc("name")).
+				Limit(10).
+				Offset(20),
+			expected: `SELECT "name", "odd""name" FROM "acme"."people" WHERE "age" > ? AND ("country" = ? OR "country" IN (?, ?)) AND "name" IS NULL ORDER BY "age" DESC, "name" LIMIT 10 OFFSET 20`,
+			args:     []interface{}{18, "NZ", "AU", "FJ"},
+		},
+		{
+			name: "postgres",
+			builder: Select("name").
+				From("acme", people).
+				Where(Not(Like("name", "a%")), Le("age", 65), In("country")).
+				Offset(5).
+				Dialect(schema.Postgres),
+			expected: `SELECT "name" FROM "acme"."people" WHERE NOT ("name" LIKE $1) AND "age" <= $2 AND 1 = 0 OFFSET 5`,
+			args:     []interface{}{"a%", 65},
+		},
+		{
+			name:     "sqlite offset",
+			builder:  Select().From("", people).Where(NotNull("age"), Ne("name", "x")).Offset(5),
+			expected: `SELECT * FROM "people" WHERE "age" IS NOT NULL AND "name" <> ? LIMIT -1 OFFSET 5`,
+			args:     []interface{}{"x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, args, err := tt.builder.Build()
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, q)
+			require.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, _, err := Select("name").Build()
+	require.ErrorIs(t, err, ErrNoSource)
+
+	_, _, err = Select("nope").From("acme", people).Build()
+	require.ErrorIs(t, err, ErrUnknownField)
+
+	_, _, err = Select().From("acme", people).Where(Or(Eq("x", 1))).Build()
+	require.ErrorIs(t, err, ErrUnknownField)
+
+	_, _, err = Select().From("acme", people).OrderBy(Asc("name; DROP TABLE people")).Build()
+	require.ErrorIs(t, err, ErrUnknownField)
+
+	_, _, err = Select().From("acme", people).Limit(-1).Build()
+	require.ErrorIs(t, err, ErrInvalidLimit)
+
+	_, _, err = Select().From("acme", people).Dialect("mysql").Build()
+	require.ErrorIs(t, err, schema.ErrUnsupportedDialect)
+}
@@ -5,15 +5,24 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"path/filepath"
 
+	"github.com/instapi/client-go/patch"
 	"github.com/instapi/client-go/record"
+	"github.com/instapi/client-go/transcode"
 	"github.com/instapi/client-go/types"
 )
 
-// GetRecords gets schema records.
+// GetRecords gets schema records. Records can be filtered, sorted and
+// projected with the Filter, Sort and Fields options.
 func (c *Client) GetRecords(ctx context.Context, account, schema string, dst interface{}, options ...RequestOption) error {
-	_, _, err := c.doRequest(
+	err := validateOptions(options)
+
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.doRequest(
 		ctx,
 		http.MethodGet,
 		types.JSON,
@@ -59,16 +68,26 @@ func (c *Client) CreateRecord(ctx context.Context, account, schema string, src,
 	return err
 }
 
-// CreateRecords makes a create records request.
+// CreateRecords makes a create records request. TSV, newline delimited JSON
+// and Avro input is transcoded into CSV or JSON on the fly, while Parquet is
+// sent as is.
 func (c *Client) CreateRecords(ctx context.Context, account, schema, contentType string, r io.Reader, options ...RequestOption) (int, error) {
+	contentType, tr, err := transcode.NewReader(contentType, r)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer tr.Close() // nolint: errcheck
+
 	var resp record.Batch
-	_, _, err := c.doRequest(
+	_, _, err = c.doRequest(
 		ctx,
 		http.MethodPost,
 		contentType,
 		c.endpoint+"accounts/"+url.PathEscape(account)+"/schemas/"+url.PathEscape(schema)+"/records",
 		http.StatusAccepted,
-		r,
+		tr,
 		&resp,
 		append(options, Param("batch", true))...,
 	)
@@ -77,22 +96,27 @@ func (c *Client) CreateRecords(ctx context.Context, account, schema, contentType
 }
 
 // CreateRecordsFromFile makes a create records request for the given file.
+// Compressed files are decompressed on the fly and the records of each
+// supported file of an archive are created in its own schema, named after the
+// file as done by ImportSchemasFromFile, rather than in the given schema.
 func (c *Client) CreateRecordsFromFile(ctx context.Context, account, schema, filename string, options ...RequestOption) (int, error) {
-	contentType, err := getContentType(filename)
-
-	if err != nil {
-		return 0, err
-	}
+	var (
+		count    int
+		archived = isArchive(filepath.Base(filename))
+	)
 
-	f, err := os.Open(filename) // nolint: gosec
+	err := c.walkFile(filename, func(entry, contentType string, r io.Reader) error {
+		if archived {
+			schema = entry
+		}
 
-	if err != nil {
-		return 0, err
-	}
+		n, err := c.CreateRecords(ctx, account, schema, contentType, r, options...)
+		count += n
 
-	defer f.Close() // nolint: gosec
+		return err
+	})
 
-	return c.CreateRecords(ctx, account, schema, contentType, f, options...)
+	return count, err
 }
 
 // UpdateRecord updates a record.
@@ -111,12 +135,24 @@ func (c *Client) UpdateRecord(ctx context.Context, account, schema, id string, s
 	return err
 }
 
-// PatchRecord patches a record.
+// PatchRecord patches a record. A patch.Patch source is sent as a RFC 6902
+// JSON Patch, a patch.Merge source as a RFC 7396 JSON Merge Patch and any
+// other source as plain JSON.
 func (c *Client) PatchRecord(ctx context.Context, account, schema, id string, src interface{}, dst interface{}, options ...RequestOption) error {
+	contentType := types.JSON
+
+	switch src.(type) {
+	case patch.Patch, *patch.Patch:
+		contentType = types.JSONPatch
+
+	case patch.Merge, *patch.Merge:
+		contentType = types.MergePatch
+	}
+
 	_, _, err := c.doRequest(
 		ctx,
 		http.MethodPatch,
-		types.JSON,
+		contentType,
 		c.endpoint+"accounts/"+url.PathEscape(account)+"/schemas/"+url.PathEscape(schema)+"/records/"+id,
 		http.StatusOK,
 		src,
@@ -127,6 +163,23 @@ func (c *Client) PatchRecord(ctx context.Context, account, schema, id string, sr
 	return err
 }
 
+// DiffAndPatchRecord computes a minimal JSON Patch between the from and to
+// record values and applies it to the record. No request is made when the
+// values are equal.
+func (c *Client) DiffAndPatchRecord(ctx context.Context, account, schema, id string, from, to interface{}, dst interface{}, options ...RequestOption) error {
+	p, err := patch.Diff(from, to)
+
+	if err != nil {
+		return err
+	}
+
+	if len(p) == 0 {
+		return nil
+	}
+
+	return c.PatchRecord(ctx, account, schema, id, p, dst, options...)
+}
+
 // DeleteRecord deletes a record.
 func (c *Client) DeleteRecord(ctx context.Context, account, schema, id string, options ...RequestOption) error {
 	_, _, err := c.doRequest(
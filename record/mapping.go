@@ -0,0 +1,268 @@
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TagName is the struct tag used to map struct fields to schema fields.
+const TagName = "instapi"
+
+// Mapping related errors.
+var (
+	ErrNotStruct = errors.New("not a struct type")
+	ErrNoID      = errors.New("record has no ID")
+)
+
+var recordType = reflect.TypeOf(Record{})
+
+// Field represents a struct field mapped to a schema field.
+type Field struct {
+	Name       string
+	GoName     string
+	Index      []int
+	Type       reflect.Type
+	PrimaryKey bool
+	OmitEmpty  bool
+	Options    map[string]string
+}
+
+// Mapping represents the mapping between a Go struct and a schema record.
+type Mapping struct {
+	Type     reflect.Type
+	Fields   []*Field
+	metadata []int
+}
+
+// NewMapping creates a mapping for the given struct type. Fields are named by
+// their `instapi:"name,pk"` tag, falling back to the json tag name and then
+// the Go field name. Fields tagged "-" are ignored and an embedded Record is
+// populated with the record metadata.
+func NewMapping(t reflect.Type) (*Mapping, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", ErrNotStruct, t)
+	}
+
+	m := &Mapping{Type: t}
+	m.addFields(t, nil)
+
+	return m, nil
+}
+
+// MappingOf creates a mapping for the type of the given value.
+func MappingOf(v interface{}) (*Mapping, error) {
+	return NewMapping(reflect.TypeOf(v))
+}
+
+func (m *Mapping) addFields(t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int{}, index...), i)
+
+		if sf.Anonymous && sf.Type == recordType {
+			m.metadata = idx
+			continue
+		}
+
+		tag, hasTag := sf.Tag.Lookup(TagName)
+
+		if tag == "-" {
+			continue
+		}
+
+		if sf.Anonymous && !hasTag && sf.Type.Kind() == reflect.Struct {
+			m.addFields(sf.Type, idx)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		f := &Field{
+			Name:    sf.Name,
+			GoName:  sf.Name,
+			Index:   idx,
+			Type:    sf.Type,
+			Options: map[string]string{},
+		}
+
+		if !hasTag {
+			tag = strings.Split(sf.Tag.Get("json"), ",")[0]
+
+			if tag == "-" {
+				continue
+			}
+		}
+
+		parts := strings.Split(tag, ",")
+
+		if parts[0] != "" {
+			f.Name = parts[0]
+		}
+
+		if hasTag {
+			for _, v := range parts[1:] {
+				k, value := v, ""
+
+				if j := strings.IndexByte(v, '='); j >= 0 {
+					k, value = v[:j], v[j+1:]
+				}
+
+				switch k {
+				case "pk":
+					f.PrimaryKey = true
+
+				case "omitempty":
+					f.OmitEmpty = true
+				}
+
+				f.Options[k] = value
+			}
+		}
+
+		m.Fields = append(m.Fields, f)
+	}
+}
+
+// PrimaryKey returns the primary key field names.
+func (m *Mapping) PrimaryKey() []string {
+	var pk []string
+
+	for _, f := range m.Fields {
+		if f.PrimaryKey {
+			pk = append(pk, f.Name)
+		}
+	}
+
+	return pk
+}
+
+// Encode encodes the given struct value as a record keyed by schema field
+// name. Record metadata is not included.
+func (m *Mapping) Encode(v interface{}) (map[string]interface{}, error) {
+	rv, err := m.value(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make(map[string]interface{}, len(m.Fields))
+
+	for _, f := range m.Fields {
+		fv := rv.FieldByIndex(f.Index)
+
+		if f.OmitEmpty && fv.IsZero() {
+			continue
+		}
+
+		dst[f.Name] = fv.Interface()
+	}
+
+	return dst, nil
+}
+
+// Decode decodes the given JSON record into the struct value pointed to by v.
+func (m *Mapping) Decode(b []byte, v interface{}) error {
+	if reflect.ValueOf(v).Kind() != reflect.Ptr {
+		return fmt.Errorf("%w: non-pointer %T", ErrNotStruct, v)
+	}
+
+	rv, err := m.value(v)
+
+	if err != nil {
+		return err
+	}
+
+	var src map[string]json.RawMessage
+	err = json.Unmarshal(b, &src)
+
+	if err != nil {
+		return err
+	}
+
+	if m.metadata != nil {
+		err = json.Unmarshal(b, rv.FieldByIndex(m.metadata).Addr().Interface())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, f := range m.Fields {
+		raw, exists := src[f.Name]
+
+		if !exists {
+			continue
+		}
+
+		err = json.Unmarshal(raw, rv.FieldByIndex(f.Index).Addr().Interface())
+
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ID returns the record ID for the given struct value, taken from the
+// embedded record metadata or else a single primary key field.
+func (m *Mapping) ID(v interface{}) (string, error) {
+	rv, err := m.value(v)
+
+	if err != nil {
+		return "", err
+	}
+
+	if m.metadata != nil {
+		if id := rv.FieldByIndex(m.metadata).Interface().(Record).ID; id != "" {
+			return id, nil
+		}
+	}
+
+	var pk []*Field
+
+	for _, f := range m.Fields {
+		if f.PrimaryKey {
+			pk = append(pk, f)
+		}
+	}
+
+	if len(pk) != 1 {
+		return "", ErrNoID
+	}
+
+	fv := rv.FieldByIndex(pk[0].Index)
+
+	if fv.IsZero() {
+		return "", ErrNoID
+	}
+
+	return fmt.Sprint(fv.Interface()), nil
+}
+
+func (m *Mapping) value(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("%w: nil %s", ErrNotStruct, rv.Type())
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Type() != m.Type {
+		return reflect.Value{}, fmt.Errorf("%w: expected %s, got %s", ErrNotStruct, m.Type, rv.Type())
+	}
+
+	return rv, nil
+}
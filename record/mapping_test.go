@@ -0,0 +1,62 @@
+package record
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type company struct {
+	Record
+	Code     string `instapi:"code,pk"`
+	Name     string `instapi:"name"`
+	Website  string `instapi:"website,omitempty"`
+	Staff    int    `json:"staff"`
+	Internal string `instapi:"-"`
+}
+
+func TestMapping(t *testing.T) {
+	m, err := MappingOf(company{})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"code"}, m.PrimaryKey())
+	require.Len(t, m.Fields, 4)
+
+	src, err := m.Encode(&company{Code: "ACME", Name: "Acme", Internal: "x"})
+
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"code": "ACME", "name": "Acme", "staff": 0}, src)
+
+	var dst company
+	err = m.Decode([]byte(`{
+		"instapi:id": "42",
+		"instapi:createdAt": "2021-01-02T03:04:05Z",
+		"code": "ACME",
+		"name": "Acme",
+		"staff": 12
+	}`), &dst)
+
+	require.NoError(t, err)
+	require.Equal(t, "42", dst.ID)
+	require.Equal(t, 12, dst.Staff)
+
+	id, err := m.ID(&dst)
+
+	require.NoError(t, err)
+	require.Equal(t, "42", id)
+
+	id, err = m.ID(&company{Code: "ACME"})
+
+	require.NoError(t, err)
+	require.Equal(t, "ACME", id)
+
+	_, err = m.ID(&company{})
+
+	require.ErrorIs(t, err, ErrNoID)
+}
+
+func TestMappingNotStruct(t *testing.T) {
+	_, err := MappingOf(1)
+
+	require.ErrorIs(t, err, ErrNotStruct)
+}
@@ -0,0 +1,40 @@
+package instapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/instapi/client-go/patch"
+	"github.com/instapi/client-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchRecord(t *testing.T) {
+	var contentTypes []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+	}))
+	defer srv.Close()
+
+	var (
+		c     = New(Endpoint(srv.URL + "/"))
+		ctx   = context.Background()
+		p     = patch.New().Replace("/name", "Acme")
+		merge = patch.Merge{"name": "Acme"}
+	)
+
+	for _, src := range []interface{}{p, &p, merge, &merge, map[string]interface{}{"name": "Acme"}} {
+		require.NoError(t, c.PatchRecord(ctx, "acme", "companies", "1", src, nil))
+	}
+
+	require.Equal(t, []string{
+		types.JSONPatch,
+		types.JSONPatch,
+		types.MergePatch,
+		types.MergePatch,
+		types.JSON,
+	}, contentTypes)
+}
@@ -0,0 +1,134 @@
+package instapi
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/instapi/client-go/record"
+)
+
+// Repository provides typed access to the records of a schema, mapping the
+// fields of T via `instapi` struct tags.
+type Repository[T any] struct {
+	client  *Client
+	mapping *record.Mapping
+	account string
+	schema  string
+}
+
+// NewRepository creates a new repository for the given account schema.
+func NewRepository[T any](c *Client, account, schema string) (*Repository[T], error) {
+	m, err := record.NewMapping(reflect.TypeOf((*T)(nil)).Elem())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository[T]{
+		client:  c,
+		mapping: m,
+		account: account,
+		schema:  schema,
+	}, nil
+}
+
+// Mapping returns the struct to schema field mapping.
+func (r *Repository[T]) Mapping() *record.Mapping {
+	return r.mapping
+}
+
+// Get gets a record.
+func (r *Repository[T]) Get(ctx context.Context, id string, options ...RequestOption) (*T, error) {
+	var b json.RawMessage
+	err := r.client.GetRecord(ctx, r.account, r.schema, id, &b, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decode(b)
+}
+
+// List gets schema records.
+func (r *Repository[T]) List(ctx context.Context, options ...RequestOption) ([]*T, error) {
+	var b []json.RawMessage
+	err := r.client.GetRecords(ctx, r.account, r.schema, &b, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := make([]*T, 0, len(b))
+
+	for _, v := range b {
+		dst, err := r.decode(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, dst)
+	}
+
+	return s, nil
+}
+
+// Create creates a record, returning the stored record.
+func (r *Repository[T]) Create(ctx context.Context, v *T, options ...RequestOption) (*T, error) {
+	src, err := r.mapping.Encode(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var b json.RawMessage
+	err = r.client.CreateRecord(ctx, r.account, r.schema, src, &b, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decode(b)
+}
+
+// Update updates a record identified by its metadata ID or primary key,
+// returning the stored record.
+func (r *Repository[T]) Update(ctx context.Context, v *T, options ...RequestOption) (*T, error) {
+	id, err := r.mapping.ID(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := r.mapping.Encode(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var b json.RawMessage
+	err = r.client.UpdateRecord(ctx, r.account, r.schema, id, src, &b, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decode(b)
+}
+
+// Delete deletes a record.
+func (r *Repository[T]) Delete(ctx context.Context, id string, options ...RequestOption) error {
+	return r.client.DeleteRecord(ctx, r.account, r.schema, id, options...)
+}
+
+func (r *Repository[T]) decode(b []byte) (*T, error) {
+	dst := new(T)
+	err := r.mapping.Decode(b, dst)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dst, nil
+}
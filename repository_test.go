@@ -0,0 +1,172 @@
+package instapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/instapi/client-go/record"
+	"github.com/stretchr/testify/require"
+)
+
+type company struct {
+	record.Record
+	Code    string `instapi:"code,pk"`
+	Name    string `instapi:"name"`
+	Website string `instapi:"website,omitempty"`
+}
+
+// recordServer serves an in-memory store of the records of a single schema
+// keyed by their code field.
+func recordServer(t *testing.T) *httptest.Server {
+	var (
+		mu      sync.Mutex
+		records = map[string]map[string]interface{}{}
+		order   []string
+	)
+
+	const prefix = "/accounts/acme/schemas/companies/records"
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+		var src map[string]interface{}
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&src))
+		}
+
+		switch {
+		case r.Method == http.MethodGet && id == "":
+			dst := make([]map[string]interface{}, 0, len(order))
+
+			for _, v := range order {
+				dst = append(dst, records[v])
+			}
+
+			_ = json.NewEncoder(w).Encode(dst)
+
+		case r.Method == http.MethodPost && id == "":
+			id = src["code"].(string)
+			src["instapi:id"] = id
+			src["instapi:createdAt"] = "2021-01-02T03:04:05Z"
+			records[id] = src
+			order = append(order, id)
+
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(src)
+
+		case records[id] == nil:
+			w.WriteHeader(http.StatusNotFound)
+
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(records[id])
+
+		case r.Method == http.MethodPut:
+			src["instapi:id"] = id
+			src["instapi:createdAt"] = records[id]["instapi:createdAt"]
+			src["instapi:updatedAt"] = "2021-02-03T04:05:06Z"
+			records[id] = src
+
+			_ = json.NewEncoder(w).Encode(src)
+
+		case r.Method == http.MethodDelete:
+			delete(records, id)
+
+			for i, v := range order {
+				if v == id {
+					order = append(order[:i], order[i+1:]...)
+					break
+				}
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+func TestRepository(t *testing.T) {
+	srv := recordServer(t)
+	defer srv.Close()
+
+	var (
+		c   = New(Endpoint(srv.URL + "/"))
+		ctx = context.Background()
+	)
+
+	repo, err := NewRepository[company](c, "acme", "companies")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"code"}, repo.Mapping().PrimaryKey())
+
+	created, err := repo.Create(ctx, &company{Code: "ACME", Name: "Acme"})
+
+	require.NoError(t, err)
+	require.Equal(t, "ACME", created.ID)
+	require.Equal(t, "Acme", created.Name)
+	require.False(t, created.CreatedAt.IsZero())
+	require.Nil(t, created.UpdatedAt)
+
+	_, err = repo.Create(ctx, &company{Code: "INIT", Name: "Initech", Website: "initech.com"})
+	require.NoError(t, err)
+
+	got, err := repo.Get(ctx, "ACME")
+
+	require.NoError(t, err)
+	require.Equal(t, created, got)
+
+	// Updates are identified by the record metadata ID
+	got.Name = "Acme Corporation"
+	updated, err := repo.Update(ctx, got)
+
+	require.NoError(t, err)
+	require.Equal(t, "Acme Corporation", updated.Name)
+	require.NotNil(t, updated.UpdatedAt)
+
+	// Or else the primary key
+	updated, err = repo.Update(ctx, &company{Code: "INIT", Name: "Initech LLC"})
+
+	require.NoError(t, err)
+	require.Equal(t, "Initech LLC", updated.Name)
+	require.Empty(t, updated.Website)
+
+	_, err = repo.Update(ctx, &company{Name: "Unknown"})
+	require.ErrorIs(t, err, record.ErrNoID)
+
+	list, err := repo.List(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "Acme Corporation", list[0].Name)
+	require.Equal(t, "INIT", list[1].Code)
+
+	require.NoError(t, repo.Delete(ctx, "ACME"))
+
+	_, err = repo.Get(ctx, "ACME")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	list, err = repo.List(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+}
+
+func TestRepositoryNotStruct(t *testing.T) {
+	_, err := NewRepository[string](New(), "acme", "companies")
+	require.ErrorIs(t, err, record.ErrNotStruct)
+}
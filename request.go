@@ -4,6 +4,30 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
+
+	"github.com/instapi/client-go/filter"
+	"github.com/instapi/client-go/internal/sample"
+	"github.com/instapi/client-go/schema"
+)
+
+// Request option errors.
+var (
+	ErrUnknownField = filter.ErrUnknownField
+)
+
+// SamplingStrategy represents a schema detection sampling strategy.
+type SamplingStrategy = sample.Strategy
+
+// Schema detection sampling strategies.
+const (
+	// SampleHead samples the leading records of the input.
+	SampleHead = sample.Head
+	// SampleReservoir samples records uniformly across the whole input.
+	SampleReservoir = sample.Reservoir
+	// SampleStratified samples records from evenly spaced segments of a
+	// seekable input, falling back to reservoir sampling.
+	SampleStratified = sample.Stratified
 )
 
 // RequestOption represents a API request option.
@@ -23,6 +47,11 @@ func Name(name string) RequestOption {
 	return Param("name", name)
 }
 
+// Account sets the account parameter, e.g. to resolve the schemas of a query.
+func Account(account string) RequestOption {
+	return Param("account", account)
+}
+
 // Limit sets the limit parameter.
 func Limit(limit int) RequestOption {
 	return Param("limit", limit)
@@ -68,6 +97,177 @@ func Headers(headers bool) RequestOption {
 	return Param("headers", headers)
 }
 
+// Filter sets the filter parameter to the encoded expression, e.g.
+// filter.Eq("country", "NZ"). A nil expression sets no filter.
+func Filter(expr filter.Expr) RequestOption {
+	return RequestOption{
+		fn: func(u *url.Values) {
+			if expr != nil {
+				u.Set("filter", expr.String())
+			}
+		},
+		param: "filter",
+		value: expr,
+	}
+}
+
+// sortOrder represents a sort option.
+type sortOrder struct {
+	field string
+	desc  bool
+}
+
+// Sort adds a sort order to the sort parameter, a comma separated list of
+// field names prefixed with - when descending. Sorts apply in the order given.
+func Sort(field string, desc bool) RequestOption {
+	key := field
+
+	if desc {
+		key = "-" + field
+	}
+
+	return RequestOption{
+		fn: func(u *url.Values) {
+			if v := u.Get("sort"); v != "" {
+				u.Set("sort", v+","+key)
+			} else {
+				u.Set("sort", key)
+			}
+		},
+		param: "sort",
+		value: sortOrder{field: field, desc: desc},
+	}
+}
+
+// Fields sets the fields parameter, projecting the response to the given
+// fields.
+func Fields(names ...string) RequestOption {
+	return RequestOption{
+		fn: func(u *url.Values) {
+			u.Set("fields", strings.Join(names, ","))
+		},
+		param: "fields",
+		value: names,
+	}
+}
+
+// ValidateOptions validates the Filter, Sort and Fields options against the
+// schema before the request is sent.
+func ValidateOptions(s *schema.Schema) RequestOption {
+	return clientParam("validate", s)
+}
+
+// validateOptions validates the record options when requested with
+// ValidateOptions.
+func validateOptions(options []RequestOption) error {
+	v, exists := findOption(options, "validate")
+
+	if !exists {
+		return nil
+	}
+
+	s := v.(*schema.Schema)
+
+	for _, option := range options {
+		var fields []string
+
+		switch v := option.value.(type) {
+		case filter.Expr:
+			if err := v.Validate(s); err != nil {
+				return err
+			}
+
+		case sortOrder:
+			fields = []string{v.field}
+
+		case []string:
+			if option.param == "fields" {
+				fields = v
+			}
+		}
+
+		for _, name := range fields {
+			if s.Field(name) == nil {
+				return fmt.Errorf("%w: %s.%s", ErrUnknownField, s.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DryRun sets the dryRun parameter, validating the query syntax and the
+// schemas and fields it references without executing it.
+func DryRun() RequestOption {
+	return Param("dryRun", true)
+}
+
+// Accept sets the accepted response content type, e.g. CSV or newline
+// delimited JSON for QueryRows.
+func Accept(contentType string) RequestOption {
+	return clientParam("accept", contentType)
+}
+
+// Sampling sets the client side sampling strategy used for schema detection.
+func Sampling(strategy SamplingStrategy) RequestOption {
+	return clientParam("sampling", strategy)
+}
+
+// SizeLimit sets the client side size limit of schema detection samples.
+func SizeLimit(limit int) RequestOption {
+	return clientParam("sizeLimit", limit)
+}
+
+// Delimiter sets the field delimiter of CSV input sampled client side for
+// schema detection, e.g. ';'. TSV input is sampled once transcoded into CSV.
+func Delimiter(delimiter rune) RequestOption {
+	return clientParam("delimiter", delimiter)
+}
+
+// clientParam sets a client side parameter which is not sent to the API.
+func clientParam(k string, v interface{}) RequestOption {
+	return RequestOption{
+		fn:    func(*url.Values) {},
+		param: k,
+		value: v,
+	}
+}
+
+// recordParams lists the parameters selecting records.
+var recordParams = []string{"limit", "skip", "offset", "filter", "sort", "fields"}
+
+// withoutParams returns the options except those of the given parameters.
+func withoutParams(options []RequestOption, params ...string) []RequestOption {
+	dst := make([]RequestOption, 0, len(options))
+
+	for _, option := range options {
+		excluded := false
+
+		for _, v := range params {
+			if option.param == v {
+				excluded = true
+			}
+		}
+
+		if !excluded {
+			dst = append(dst, option)
+		}
+	}
+
+	return dst
+}
+
+// findOption returns the value of the last option with the given parameter.
+func findOption(options []RequestOption, param string) (interface{}, bool) {
+	for i := len(options) - 1; i >= 0; i-- {
+		if options[i].param == param {
+			return options[i].value, true
+		}
+	}
+
+	return nil, false
+}
+
 // Param sets given URL parameter with the given value.
 func Param(k string, v interface{}) RequestOption {
 	value := ""
@@ -0,0 +1,68 @@
+package instapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/instapi/client-go/filter"
+	"github.com/instapi/client-go/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRecordsOptions(t *testing.T) {
+	var query url.Values
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	var (
+		c      = New(Endpoint(srv.URL + "/"))
+		people = &schema.Schema{
+			Name: "people",
+			Fields: []*schema.Field{
+				{Name: "name", Type: schema.String},
+				{Name: "age", Type: schema.Integer},
+			},
+		}
+		dst []map[string]interface{}
+	)
+
+	err := c.GetRecords(context.Background(), "acme", "people", &dst,
+		Filter(filter.And(filter.Gt("age", 18), filter.In("name", "a", "b"))),
+		Sort("age", true),
+		Sort("name", false),
+		Fields("name", "age"),
+		Limit(10),
+		ValidateOptions(people),
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, `and(gt(age,18),in(name,["a","b"]))`, query.Get("filter"))
+	require.Equal(t, "-age,name", query.Get("sort"))
+	require.Equal(t, "name,age", query.Get("fields"))
+	require.Equal(t, "10", query.Get("limit"))
+	require.Empty(t, query.Get("validate"))
+
+	query = nil
+
+	for _, option := range []RequestOption{
+		Filter(filter.Eq("nope", 1)),
+		Sort("nope", false),
+		Fields("name", "nope"),
+	} {
+		err = c.GetRecords(context.Background(), "acme", "people", &dst, option, ValidateOptions(people))
+
+		require.ErrorIs(t, err, ErrUnknownField)
+	}
+
+	require.Nil(t, query)
+
+	require.NoError(t, c.GetRecords(context.Background(), "acme", "people", &dst, Filter(nil), ValidateOptions(people)))
+	require.NotContains(t, query, "filter")
+}
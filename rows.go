@@ -0,0 +1,603 @@
+package instapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/instapi/client-go/types"
+)
+
+// Rows errors.
+var (
+	ErrRowsClosed         = errors.New("rows are closed")
+	ErrNoRow              = errors.New("no current row")
+	ErrScanArgs           = errors.New("wrong number of scan destinations")
+	ErrUnexpectedResponse = errors.New("unexpected query response")
+)
+
+// rowDecoder decodes the next row of a response, returning its column names
+// and values. It returns io.EOF after the last row.
+type rowDecoder interface {
+	next() ([]string, []interface{}, error)
+}
+
+// Rows is a cursor over the results of a query decoded incrementally from the
+// response body. Columns are in order of first appearance; with JSON results
+// the columns may grow as rows holding new keys are read.
+type Rows struct {
+	body    io.ReadCloser
+	dec     rowDecoder
+	columns []string
+	index   map[string]int
+	row     []interface{}
+	pending []interface{}
+	started bool
+	err     error
+}
+
+// QueryRows performs a SQL query, returning a cursor over its results.
+// Arguments are bound as with Query. The results are JSON unless a CSV, TSV
+// or NDJSON response is requested using the Accept option. The rows must be
+// closed.
+func (c *Client) QueryRows(ctx context.Context, query string, args []interface{}, options ...RequestOption) (*Rows, error) {
+	resp, contentType, err := c.queryStream(ctx, query, args, append([]RequestOption{Accept(types.JSON)}, options...))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newRows(resp.Body, contentType)
+}
+
+// queryStream performs a SQL query, returning the response with its body
+// unread along with its media type, defaulting to the accepted type.
+func (c *Client) queryStream(ctx context.Context, query string, args []interface{}, options []RequestOption) (*http.Response, string, error) {
+	var resp *http.Response
+
+	err := c.query(query, args, nil, func(contentType string, src interface{}) error {
+		var err error
+		resp, err = c.doStream(ctx, http.MethodPost, contentType, c.endpoint+"query", http.StatusOK, src, options...)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	t, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	if err != nil {
+		accept, _ := findOption(options, "accept")
+		t, _ = accept.(string)
+	}
+
+	return resp, t, nil
+}
+
+func newRows(body io.ReadCloser, contentType string) (*Rows, error) {
+	r := &Rows{body: body, index: map[string]int{}}
+
+	switch contentType {
+	case types.CSV, types.TSV:
+		dec := csv.NewReader(body)
+		dec.ReuseRecord = true
+
+		if contentType == types.TSV {
+			dec.Comma = '\t'
+			dec.LazyQuotes = true
+		}
+
+		r.dec = &csvRows{dec: dec}
+
+	case types.NDJSON:
+		dec := json.NewDecoder(body)
+		dec.UseNumber()
+		r.dec = &ndjsonRows{dec: dec}
+
+	default:
+		dec := json.NewDecoder(body)
+		dec.UseNumber()
+		r.dec = &jsonRows{dec: dec}
+	}
+
+	// Read the first row so the columns are known before calling Next
+	r.pending, r.err = r.read()
+
+	if r.err == io.EOF {
+		r.err = nil
+	}
+
+	if r.err != nil {
+		body.Close() // nolint: errcheck
+		return nil, r.err
+	}
+
+	return r, nil
+}
+
+// read decodes the next row, adding any new columns.
+func (r *Rows) read() ([]interface{}, error) {
+	keys, values, err := r.dec.next()
+
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]interface{}, len(r.columns), len(r.columns)+len(keys))
+
+	for i, k := range keys {
+		j, exists := r.index[k]
+
+		if !exists {
+			j = len(r.columns)
+			r.index[k] = j
+			r.columns = append(r.columns, k)
+			row = append(row, nil)
+		}
+
+		row[j] = values[i]
+	}
+
+	return row, nil
+}
+
+// Next prepares the next row for Scan, returning false after the last row or
+// on error, in which case Err returns the error. The rows are closed once
+// exhausted.
+func (r *Rows) Next() bool {
+	if r.body == nil {
+		return false
+	}
+
+	var err error
+
+	if !r.started {
+		r.started = true
+		r.row = r.pending
+		r.pending = nil
+	} else {
+		r.row, err = r.read()
+	}
+
+	if r.row == nil && err == nil {
+		err = io.EOF
+	}
+
+	if err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+
+		r.row = nil
+		r.Close() // nolint: errcheck
+
+		return false
+	}
+
+	return true
+}
+
+// Scan copies the columns of the current row into the destinations, which
+// must match the number of columns. Destinations may be sql.Scanner
+// implementations, pointers to interface{}, string, []byte, json.RawMessage,
+// time.Time, numeric and bool types, pointers to these, or any type the JSON
+// encoding of the value can be unmarshaled into.
+func (r *Rows) Scan(dest ...interface{}) error {
+	if r.row == nil {
+		if r.body == nil {
+			return ErrRowsClosed
+		}
+
+		return ErrNoRow
+	}
+
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf("%w: expected %d, got %d", ErrScanArgs, len(r.columns), len(dest))
+	}
+
+	for i, v := range dest {
+		var value interface{}
+
+		if i < len(r.row) {
+			value = r.row[i]
+		}
+
+		if err := scanValue(v, value); err != nil {
+			return fmt.Errorf("column %q: %w", r.columns[i], err)
+		}
+	}
+
+	return nil
+}
+
+// Columns returns the column names read so far.
+func (r *Rows) Columns() ([]string, error) {
+	if r.body == nil {
+		return nil, ErrRowsClosed
+	}
+
+	return r.columns, nil
+}
+
+// Err returns the error encountered while iterating, if any.
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Close closes the response body. It is safe to call multiple times.
+func (r *Rows) Close() error {
+	if r.body == nil {
+		return nil
+	}
+
+	err := r.body.Close()
+	r.body = nil
+	r.row = nil
+
+	return err
+}
+
+// jsonRows decodes a JSON array of objects.
+type jsonRows struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func (d *jsonRows) next() ([]string, []interface{}, error) {
+	if !d.started {
+		d.started = true
+		t, err := d.dec.Token()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// An empty result may be null
+		if t == nil {
+			return nil, nil, io.EOF
+		}
+
+		if t != json.Delim('[') {
+			return nil, nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, t)
+		}
+	}
+
+	if !d.dec.More() {
+		_, err := d.dec.Token()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, io.EOF
+	}
+
+	return decodeObject(d.dec)
+}
+
+// ndjsonRows decodes newline delimited JSON objects.
+type ndjsonRows struct {
+	dec *json.Decoder
+}
+
+func (d *ndjsonRows) next() ([]string, []interface{}, error) {
+	if !d.dec.More() {
+		return nil, nil, io.EOF
+	}
+
+	return decodeObject(d.dec)
+}
+
+// csvRows decodes delimited text with a header row.
+type csvRows struct {
+	dec    *csv.Reader
+	header []string
+}
+
+func (d *csvRows) next() ([]string, []interface{}, error) {
+	if d.header == nil {
+		header, err := d.dec.Read()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		d.header = append([]string(nil), header...)
+	}
+
+	record, err := d.dec.Read()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]interface{}, len(record))
+
+	for i, v := range record {
+		values[i] = v
+	}
+
+	return d.header, values, nil
+}
+
+// decodeObject decodes the next JSON object, returning its keys in order
+// along with their values.
+func decodeObject(dec *json.Decoder) ([]string, []interface{}, error) {
+	t, err := dec.Token()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t != json.Delim('{') {
+		return nil, nil, fmt.Errorf("%w: row %v", ErrUnexpectedResponse, t)
+	}
+
+	var (
+		keys   []string
+		values []interface{}
+	)
+
+	for dec.More() {
+		t, err = dec.Token()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		k, _ := t.(string)
+
+		var v interface{}
+
+		if err = dec.Decode(&v); err != nil {
+			return nil, nil, err
+		}
+
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	_, err = dec.Token()
+
+	return keys, values, err
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// scanValue assigns a decoded JSON or CSV value to the destination.
+func scanValue(dest, v interface{}) error {
+	switch d := dest.(type) {
+	case sql.Scanner:
+		return d.Scan(scannerValue(v))
+
+	case *interface{}:
+		*d = plainValue(v)
+		return nil
+
+	case *json.RawMessage:
+		b, err := json.Marshal(v)
+
+		if err != nil {
+			return err
+		}
+
+		*d = b
+
+		return nil
+
+	case *[]byte:
+		switch v := v.(type) {
+		case nil:
+			*d = nil
+
+		case string:
+			*d = []byte(v)
+
+		default:
+			b, err := json.Marshal(v)
+
+			if err != nil {
+				return err
+			}
+
+			*d = b
+		}
+
+		return nil
+	}
+
+	rv := reflect.ValueOf(dest)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w: destination %T is not a non-nil pointer", ErrUnsupportedType, dest)
+	}
+
+	return assignValue(rv.Elem(), v)
+}
+
+func assignValue(dst reflect.Value, v interface{}) error {
+	if dst.Kind() == reflect.Ptr {
+		if v == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+
+		if dst.Type().Implements(scannerType) {
+			return dst.Interface().(sql.Scanner).Scan(scannerValue(v))
+		}
+
+		return assignValue(dst.Elem(), v)
+	}
+
+	if dst.Type() == timeType {
+		s, ok := v.(string)
+
+		if !ok {
+			return fmt.Errorf("%w: cannot scan %T into time.Time", ErrUnsupportedType, v)
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, s)
+
+		if err != nil {
+			return err
+		}
+
+		dst.Set(reflect.ValueOf(t))
+
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(stringValue(v))
+		return nil
+
+	case reflect.Bool:
+		switch v := v.(type) {
+		case bool:
+			dst.SetBool(v)
+			return nil
+
+		case string, json.Number:
+			b, err := strconv.ParseBool(stringValue(v))
+
+			if err != nil {
+				return err
+			}
+
+			dst.SetBool(b)
+
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isScalar(v) {
+			n, err := strconv.ParseInt(stringValue(v), 10, dst.Type().Bits())
+
+			if err != nil {
+				return err
+			}
+
+			dst.SetInt(n)
+
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if isScalar(v) {
+			n, err := strconv.ParseUint(stringValue(v), 10, dst.Type().Bits())
+
+			if err != nil {
+				return err
+			}
+
+			dst.SetUint(n)
+
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if isScalar(v) {
+			f, err := strconv.ParseFloat(stringValue(v), dst.Type().Bits())
+
+			if err != nil {
+				return err
+			}
+
+			dst.SetFloat(f)
+
+			return nil
+		}
+	}
+
+	if v == nil {
+		return fmt.Errorf("%w: cannot scan NULL into %s", ErrUnsupportedType, dst.Type())
+	}
+
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, dst.Addr().Interface())
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case json.Number, string:
+		return true
+	}
+
+	return false
+}
+
+// stringValue formats the value as text, JSON encoding objects and arrays.
+func stringValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+
+	case string:
+		return v
+
+	case json.Number:
+		return v.String()
+
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	b, _ := json.Marshal(v)
+
+	return string(b)
+}
+
+// plainValue converts JSON numbers to int64 or float64.
+func plainValue(v interface{}) interface{} {
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := n.Float64()
+
+		return f
+	}
+
+	return v
+}
+
+// scannerValue converts the value to a driver.Value for sql.Scanner
+// implementations, JSON encoding objects and arrays.
+func scannerValue(v interface{}) interface{} {
+	switch v.(type) {
+	case nil, string, bool:
+		return v
+
+	case json.Number:
+		return plainValue(v)
+	}
+
+	b, _ := json.Marshal(v)
+
+	return b
+}
@@ -0,0 +1,130 @@
+package instapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/instapi/client-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func queryRowsServer(t *testing.T, contentType, body string) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/query", r.URL.Path)
+		require.Equal(t, contentType, r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return New(Endpoint(srv.URL + "/"))
+}
+
+func TestQueryRowsJSON(t *testing.T) {
+	c := queryRowsServer(t, types.JSON, `[
+		{"id": 1, "name": "a", "tags": ["x"], "at": "2021-01-02T03:04:05Z"},
+		{"id": 2, "name": null, "tags": null, "at": null, "extra": true}
+	]`)
+
+	rows, err := c.QueryRows(context.Background(), "SELECT * FROM t", nil)
+	require.NoError(t, err)
+
+	defer rows.Close() // nolint: errcheck
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name", "tags", "at"}, columns)
+
+	var (
+		id   int
+		name sql.NullString
+		tags []string
+		at   *time.Time
+	)
+
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&id, &name, &tags, &at))
+	require.Equal(t, 1, id)
+	require.Equal(t, "a", name.String)
+	require.Equal(t, []string{"x"}, tags)
+	require.Equal(t, time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), *at)
+
+	var (
+		raw   json.RawMessage
+		extra interface{}
+	)
+
+	require.True(t, rows.Next())
+	require.ErrorIs(t, rows.Scan(&id, &name, &raw, &at), ErrScanArgs)
+	require.NoError(t, rows.Scan(&id, &name, &raw, &at, &extra))
+	require.False(t, name.Valid)
+	require.Equal(t, "null", string(raw))
+	require.Nil(t, at)
+	require.Equal(t, true, extra)
+
+	require.False(t, rows.Next())
+	require.NoError(t, rows.Err())
+	require.ErrorIs(t, rows.Scan(&id), ErrRowsClosed)
+}
+
+func TestQueryRowsNDJSON(t *testing.T) {
+	c := queryRowsServer(t, types.NDJSON, "{\"n\": 1.5}\n{\"n\": 2}\n")
+
+	rows, err := c.QueryRows(context.Background(), "SELECT n FROM t WHERE n > ?", []interface{}{1}, Accept(types.NDJSON))
+	require.NoError(t, err)
+
+	defer rows.Close() // nolint: errcheck
+
+	var values []interface{}
+
+	for rows.Next() {
+		var v interface{}
+
+		require.NoError(t, rows.Scan(&v))
+		values = append(values, v)
+	}
+
+	require.NoError(t, rows.Err())
+	require.Equal(t, []interface{}{1.5, int64(2)}, values)
+}
+
+func TestQueryRowsCSV(t *testing.T) {
+	c := queryRowsServer(t, types.CSV, "id,score,active\n1,2.5,true\n")
+
+	rows, err := c.QueryRows(context.Background(), "SELECT * FROM t", nil, Accept(types.CSV))
+	require.NoError(t, err)
+
+	defer rows.Close() // nolint: errcheck
+
+	var (
+		id     int64
+		score  float32
+		active bool
+	)
+
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&id, &score, &active))
+	require.Equal(t, int64(1), id)
+	require.Equal(t, float32(2.5), score)
+	require.True(t, active)
+	require.False(t, rows.Next())
+	require.NoError(t, rows.Err())
+}
+
+func TestQueryRowsInvalid(t *testing.T) {
+	c := queryRowsServer(t, types.JSON, `[{"id": 1}, 2]`)
+
+	rows, err := c.QueryRows(context.Background(), "SELECT * FROM t", nil)
+	require.NoError(t, err)
+
+	require.True(t, rows.Next())
+	require.False(t, rows.Next())
+	require.ErrorIs(t, rows.Err(), ErrUnexpectedResponse)
+	require.NoError(t, rows.Close())
+}
@@ -1,19 +1,35 @@
 package instapi
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"path/filepath"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/instapi/client-go/internal/sample"
+	"github.com/instapi/client-go/patch"
 	"github.com/instapi/client-go/schema"
+	"github.com/instapi/client-go/transcode"
 	"github.com/instapi/client-go/types"
 )
 
-const detectSizeLimit = 4 * 1024 * 1024
+const (
+	detectSizeLimit   = 4 * 1024 * 1024
+	backfillBatchSize = 100
+)
+
+// Schema related errors.
+var (
+	ErrBreakingChange  = errors.New("migration contains breaking changes")
+	ErrDuplicateSchema = errors.New("duplicate schema")
+	ErrRenameConflict  = errors.New("field renamed to an existing field")
+)
 
 // GetSchema gets the given schema.
 func (c *Client) GetSchema(ctx context.Context, account, name string, options ...RequestOption) (*schema.Schema, error) {
@@ -59,76 +75,258 @@ func (c *Client) GetSchemas(ctx context.Context, account string, options ...Requ
 	return s, next, nil
 }
 
-// ImportSchemasFromFile attempts to import the schemas and records from the given file.
-func (c *Client) ImportSchemasFromFile(ctx context.Context, account, filename string, options ...RequestOption) ([]*schema.Import, error) {
-	contentType, err := getContentType(filename)
+// GetAllSchemas gets every schema of the account, following pagination.
+func (c *Client) GetAllSchemas(ctx context.Context, account string, options ...RequestOption) ([]*schema.Schema, error) {
+	var (
+		s    []*schema.Schema
+		next string
+	)
 
-	if err != nil {
-		return nil, err
+	for {
+		opts := options
+
+		if next != "" {
+			opts = append(opts[:len(opts):len(opts)], Offset(next))
+		}
+
+		page, n, err := c.GetSchemas(ctx, account, opts...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, page...)
+
+		if n == "" || n == next {
+			return s, nil
+		}
+
+		next = n
 	}
+}
+
+// ImportSchemasFromFile attempts to import the schemas and records from the
+// given file. Compressed files are decompressed on the fly and each supported
+// file of an archive is imported into its own schema, named after the file.
+func (c *Client) ImportSchemasFromFile(ctx context.Context, account, filename string, options ...RequestOption) ([]*schema.Import, error) {
+	var (
+		s        []*schema.Import
+		archived = isArchive(filepath.Base(filename))
+	)
+
+	err := c.walkFile(filename, func(name, contentType string, r io.Reader) error {
+		contentType, tr, err := transcode.NewReader(contentType, r)
+
+		if err != nil {
+			return err
+		}
+
+		defer tr.Close() // nolint: errcheck
+
+		opts := options
 
-	f, err := os.Open(filename) // nolint: gosec
+		if archived {
+			opts = append(opts[:len(opts):len(opts)], Name(name))
+		}
+
+		var imports []*schema.Import
+		_, _, err = c.doRequest(
+			ctx,
+			http.MethodPost,
+			contentType,
+			c.endpoint+"accounts/"+url.PathEscape(account)+"/import",
+			http.StatusOK,
+			io.LimitReader(tr, detectSizeLimit),
+			&imports,
+			opts...,
+		)
+		s = append(s, imports...)
+
+		return err
+	})
+
+	return s, err
+}
+
+// DetectSchemasFromFile attempts to detect the schema for the given file.
+// Compressed files are decompressed on the fly and the schema of each
+// supported file of an archive is detected, named after the file.
+func (c *Client) DetectSchemasFromFile(ctx context.Context, name, filename string, options ...RequestOption) ([]*schema.Schema, error) {
+	var (
+		s        []*schema.Schema
+		archived = isArchive(filepath.Base(filename))
+	)
+
+	err := c.walkFile(filename, func(entry, contentType string, r io.Reader) error {
+		if archived {
+			name = entry
+		}
+
+		detected, err := c.DetectSchemas(ctx, name, contentType, r, options...)
+		s = append(s, detected...)
+
+		return err
+	})
+
+	return s, err
+}
+
+// DetectSchemas attempts to detect the schema for the given reader. CSV and
+// JSON input is sampled into a well-formed document of at most the size
+// limit, using the head sampling strategy unless set with Sampling. Parquet
+// files are sent whole, and other input is truncated to the size limit.
+func (c *Client) DetectSchemas(ctx context.Context, name, contentType string, r io.Reader, options ...RequestOption) ([]*schema.Schema, error) {
+	contentType, body, err := detectSample(contentType, r, options)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer f.Close() // nolint: gosec
+	defer body.Close() // nolint: errcheck
 
-	var s []*schema.Import
+	var s []*schema.Schema
 	_, _, err = c.doRequest(
 		ctx,
 		http.MethodPost,
 		contentType,
-		c.endpoint+"accounts/"+url.PathEscape(account)+"/import",
+		c.endpoint+"detect",
 		http.StatusOK,
-		io.LimitReader(f, detectSizeLimit),
+		body,
 		&s,
-		options...,
+		append(options, Name(name))...,
 	)
 
 	return s, err
 }
 
-// DetectSchemasFromFile attempts to detect the schema for the given file.
-func (c *Client) DetectSchemasFromFile(ctx context.Context, name, filename string, options ...RequestOption) ([]*schema.Schema, error) {
-	contentType, err := getContentType(filename)
+// detectSample transcodes and samples r, returning the sample content type.
+// The sample must be closed.
+func detectSample(contentType string, r io.Reader, options []RequestOption) (string, io.ReadCloser, error) {
+	s := &sample.Sampler{Limit: detectSizeLimit, Comma: delimiter(contentType, options)}
+	contentType, tr, err := transcode.NewReader(contentType, r)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if v, exists := findOption(options, "sizeLimit"); exists {
+		s.Limit = v.(int)
+	}
+
+	if v, exists := findOption(options, "sampling"); exists {
+		s.Strategy = v.(SamplingStrategy)
+	}
+
+	var b []byte
+
+	switch contentType {
+	case types.CSV:
+		b, err = s.CSV(tr)
+
+	case types.JSON:
+		b, err = s.JSON(tr)
+
+	// Parquet files hold their schema in the footer, so are sent whole
+	case types.Parquet:
+		return contentType, tr, nil
+
+	default:
+		return contentType, &limitedReader{Reader: io.LimitReader(tr, int64(s.Limit)), Closer: tr}, nil
+	}
+
+	// Sampling may stop before the end, e.g. with the head strategy
+	tr.Close() // nolint: errcheck
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return contentType, io.NopCloser(bytes.NewReader(b)), nil
+}
+
+// delimiter returns the field delimiter of the given content type once
+// transcoded, set with the Delimiter option for CSV. TSV is transcoded into
+// CSV, so that its delimiter is the default comma.
+func delimiter(contentType string, options []RequestOption) rune {
+	if v, exists := findOption(options, "delimiter"); exists && contentType == types.CSV {
+		return v.(rune)
+	}
+
+	return 0
+}
+
+// limitedReader closes the reader it limits.
+type limitedReader struct {
+	io.Reader
+	io.Closer
+}
+
+// DetectionComparison represents the local and server detection results for
+// the same input. Plans describe the changes from the server detected schema
+// to the locally detected schema of the same name.
+type DetectionComparison struct {
+	Local  []*schema.Schema
+	Server []*schema.Schema
+	Plans  []*schema.Plan
+}
+
+// CompareDetection detects the schemas of the given reader both locally and
+// on the server, using the same sample for each.
+func (c *Client) CompareDetection(ctx context.Context, name, contentType string, r io.Reader, options ...RequestOption) (*DetectionComparison, error) {
+	var detectOptions []schema.DetectOption
+
+	if d := delimiter(contentType, options); d != 0 {
+		detectOptions = append(detectOptions, schema.Delimiter(d))
+	}
+
+	contentType, body, err := detectSample(contentType, r, options)
 
 	if err != nil {
 		return nil, err
 	}
 
-	f, err := os.Open(filename) // nolint: gosec
+	defer body.Close() // nolint: errcheck
+
+	b, err := io.ReadAll(body)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer f.Close() // nolint: gosec
+	local, err := schema.Detect(bytes.NewReader(b), name, contentType, detectOptions...)
 
-	return c.DetectSchemas(ctx, name, contentType, f, options...)
-}
+	if err != nil {
+		return nil, err
+	}
 
-// DetectSchemas attempts to detect the schema for the given reader.
-func (c *Client) DetectSchemas(ctx context.Context, name, contentType string, r io.Reader, options ...RequestOption) ([]*schema.Schema, error) {
-	var s []*schema.Schema
-	_, _, err := c.doRequest(
-		ctx,
-		http.MethodPost,
-		contentType,
-		c.endpoint+"detect",
-		http.StatusOK,
-		io.LimitReader(r, detectSizeLimit),
-		&s,
-		append(options, Name(name))...,
-	)
+	server, err := c.DetectSchemas(ctx, name, contentType, bytes.NewReader(b), append(options, Sampling(SampleHead))...)
 
-	return s, err
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &DetectionComparison{Local: local, Server: server}
+
+	for _, v := range server {
+		for _, l := range local {
+			if l.Name == v.Name || len(local) == 1 && len(server) == 1 {
+				cmp.Plans = append(cmp.Plans, schema.Diff(v, l))
+			}
+		}
+	}
+
+	return cmp, nil
 }
 
-// CreateSchema creates a new schema.
+// CreateSchema creates a new schema. Fields of unknown types are rejected.
 func (c *Client) CreateSchema(ctx context.Context, account string, s *schema.Schema, options ...RequestOption) error {
-	_, _, err := c.doRequest(
+	err := s.CheckTypes()
+
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.doRequest(
 		ctx,
 		http.MethodPost,
 		types.JSON,
@@ -142,6 +340,166 @@ func (c *Client) CreateSchema(ctx context.Context, account string, s *schema.Sch
 	return err
 }
 
+// UpdateSchema updates the given schema. Fields of unknown types are rejected.
+func (c *Client) UpdateSchema(ctx context.Context, account, name string, s *schema.Schema, options ...RequestOption) error {
+	err := s.CheckTypes()
+
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.doRequest(
+		ctx,
+		http.MethodPut,
+		types.JSON,
+		c.endpoint+"accounts/"+url.PathEscape(account)+"/schemas/"+url.PathEscape(name),
+		http.StatusOK,
+		s,
+		nil,
+		options...,
+	)
+
+	return err
+}
+
+// ApplyMigration applies a migration plan computed by schema.Diff, updating
+// the schema and backfilling existing records. Plans with breaking changes
+// are rejected unless explicitly allowed. Renamed fields are first added
+// alongside the fields they replace and their values copied over before the
+// schema is updated, so that no data is lost. Options apply to every request
+// made, except those selecting records, e.g. Limit and Skip, as every record
+// is migrated.
+func (c *Client) ApplyMigration(ctx context.Context, account string, p *schema.Plan, options ...RequestOption) error {
+	if p.Breaking() && !p.AllowBreaking {
+		return fmt.Errorf("%w: schema %s", ErrBreakingChange, p.From.Name)
+	}
+
+	options = withoutParams(options, recordParams...)
+	renames := p.Renames()
+
+	if len(renames) > 0 {
+		s, err := renameSchema(p, renames)
+
+		if err != nil {
+			return err
+		}
+
+		err = c.UpdateSchema(ctx, account, p.From.Name, s, options...)
+
+		if err != nil {
+			return err
+		}
+
+		err = c.patchRecords(ctx, account, p.From.Name, func(r map[string]interface{}) patch.Merge {
+			m := patch.Merge{}
+
+			for from, to := range renames {
+				if r[from] != nil {
+					m[to] = r[from]
+				}
+			}
+
+			return m
+		}, options)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(p.Changes) > 0 {
+		err := c.UpdateSchema(ctx, account, p.From.Name, p.To, options...)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(p.Backfill) == 0 {
+		return nil
+	}
+
+	return c.patchRecords(ctx, account, p.To.Name, func(r map[string]interface{}) patch.Merge {
+		m := patch.Merge{}
+
+		for k, v := range p.Backfill {
+			if r[k] == nil {
+				m[k] = v
+			}
+		}
+
+		return m
+	}, options)
+}
+
+// renameSchema returns the schema before the migration with the renamed
+// fields added as optional fields, so that values can be copied over. Renames
+// to fields missing from the target schema are dropped.
+func renameSchema(p *schema.Plan, renames map[string]string) (*schema.Schema, error) {
+	s := *p.From
+	s.Fields = append([]*schema.Field(nil), p.From.Fields...)
+
+	for _, v := range p.From.Fields {
+		to, exists := renames[v.Name]
+
+		if !exists {
+			continue
+		}
+
+		if p.From.Field(to) != nil {
+			return nil, fmt.Errorf("%w: %s renamed to %s", ErrRenameConflict, v.Name, to)
+		}
+
+		n := p.To.Field(to)
+
+		if n == nil {
+			delete(renames, v.Name)
+			continue
+		}
+
+		f := *n
+		f.Required = false
+
+		// Fields renamed by mapping keep it once the migration is done
+		f.Mapping = ""
+		s.Fields = append(s.Fields, &f)
+	}
+
+	return &s, nil
+}
+
+// patchRecords merge patches every record of the schema with the patch
+// returned by fn, unless empty.
+func (c *Client) patchRecords(ctx context.Context, account, name string, fn func(map[string]interface{}) patch.Merge, options []RequestOption) error {
+	for skip := 0; ; skip += backfillBatchSize {
+		var records []map[string]interface{}
+		err := c.GetRecords(ctx, account, name, &records, append(options, Limit(backfillBatchSize), Skip(skip))...)
+
+		if err != nil {
+			return err
+		}
+
+		for _, r := range records {
+			m := fn(r)
+			id, _ := r["instapi:id"].(string)
+
+			if len(m) == 0 || id == "" {
+				continue
+			}
+
+			err = c.PatchRecord(ctx, account, name, id, m, nil, options...)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(records) < backfillBatchSize {
+			return nil
+		}
+	}
+}
+
 func (c *Client) createSchemas(ctx context.Context, account string, s []*schema.Schema, options ...RequestOption) error {
 	var g errgroup.Group
 
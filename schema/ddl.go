@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Dialect represents a SQL dialect.
+type Dialect string
+
+// Supported SQL dialects.
+const (
+	SQLite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+)
+
+// DDL errors.
+var (
+	ErrUnsupportedDialect = errors.New("unsupported SQL dialect")
+)
+
+var columnTypes = map[Dialect]map[FieldType]string{
+	SQLite: {
+		Integer: "INTEGER",
+		Number:  "REAL",
+		Decimal: "NUMERIC",
+		Boolean: "INTEGER",
+	},
+	Postgres: {
+		UUID:      "UUID",
+		Integer:   "BIGINT",
+		Number:    "DOUBLE PRECISION",
+		Decimal:   "NUMERIC",
+		Boolean:   "BOOLEAN",
+		Date:      "DATE",
+		DateTime:  "TIMESTAMPTZ",
+		Timestamp: "TIMESTAMPTZ",
+		Time:      "TIME",
+		JSON:      "JSONB",
+		Object:    "JSONB",
+		Array:     "JSONB",
+	},
+}
+
+// DDL renders the CREATE TABLE statement for the schema in the given
+// dialect, followed by a CREATE INDEX statement for each indexed field.
+func DDL(s *Schema, dialect Dialect) (string, error) {
+	types, exists := columnTypes[dialect]
+
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedDialect, dialect)
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "CREATE TABLE %s (\n", QuoteIdentifier(s.Name))
+
+	for i, f := range s.Fields {
+		columnType, exists := types[f.Type]
+
+		if !exists {
+			columnType = "TEXT"
+		}
+
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+
+		fmt.Fprintf(&sb, "  %s %s", QuoteIdentifier(f.Name), columnType)
+
+		if f.Required {
+			sb.WriteString(" NOT NULL")
+		}
+	}
+
+	if len(s.PrimaryKey) > 0 {
+		fmt.Fprintf(&sb, ",\n  PRIMARY KEY (%s)", quoteIdentifiers(s.PrimaryKey))
+	}
+
+	sb.WriteString("\n);\n")
+
+	for _, v := range s.Indexed {
+		fmt.Fprintf(&sb, "CREATE INDEX %s ON %s (%s);\n",
+			QuoteIdentifier(s.Name+"_"+v+"_idx"), QuoteIdentifier(s.Name), QuoteIdentifier(v))
+	}
+
+	return sb.String(), nil
+}
+
+// QuoteIdentifier quotes a SQL identifier, escaping embedded quotes.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteIdentifiers(names []string) string {
+	s := make([]string, len(names))
+
+	for i, v := range names {
+		s[i] = QuoteIdentifier(v)
+	}
+
+	return strings.Join(s, ", ")
+}
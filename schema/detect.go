@@ -0,0 +1,522 @@
+package schema
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/instapi/client-go/types"
+)
+
+// DefaultSampleSize is the default number of records sampled by detection.
+const DefaultSampleSize = 1000
+
+// Detection errors.
+var (
+	ErrUnsupportedContentType = errors.New("unsupported detection content type")
+)
+
+var (
+	dateLayouts = []string{
+		DateLayout, "2006/01/02", "02/01/2006", "01/02/2006", "02.01.2006",
+		"2 Jan 2006", "Jan 2, 2006", "2 January 2006", "January 2, 2006",
+	}
+	dateTimeLayouts = []string{
+		DateTimeLayout, time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05",
+		"2006-01-02 15:04", "02/01/2006 15:04:05", "01/02/2006 15:04:05", time.RFC1123,
+	}
+	timeLayouts = []string{TimeLayout, "15:04", "3:04PM", "3:04 PM"}
+)
+
+// DetectOption represents a detection option.
+type DetectOption func(*detector)
+
+// SampleSize option sets the maximum number of records inspected.
+func SampleSize(n int) DetectOption {
+	return func(d *detector) {
+		d.sampleSize = n
+	}
+}
+
+// Delimiter option sets the CSV field delimiter.
+func Delimiter(r rune) DetectOption {
+	return func(d *detector) {
+		d.delimiter = r
+	}
+}
+
+type detector struct {
+	name       string
+	sampleSize int
+	delimiter  rune
+	columns    []*column
+	index      map[string]*column
+	rows       int
+}
+
+// column holds the inference state of a single field.
+type column struct {
+	name      string
+	present   int
+	empty     int
+	values    map[string]bool
+	unique    bool
+	kinds     map[FieldType]bool
+	layouts   map[FieldType][]string
+	boolWords map[string]bool
+}
+
+// Detect infers schemas locally from a CSV, TSV, JSON or NDJSON reader
+// without uploading it, inspecting up to the sample size of records.
+func Detect(r io.Reader, name, contentType string, options ...DetectOption) ([]*Schema, error) {
+	switch contentType {
+	case types.CSV:
+		return DetectCSV(r, name, options...)
+
+	case types.TSV:
+		return DetectCSV(r, name, append([]DetectOption{Delimiter('\t')}, options...)...)
+
+	case types.JSON, types.NDJSON:
+		return DetectJSON(r, name, options...)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+}
+
+// DetectCSV infers the schema of the CSV records read from r. The first row
+// is expected to hold the column headers.
+func DetectCSV(r io.Reader, name string, options ...DetectOption) ([]*Schema, error) {
+	d := newDetector(name, options)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	if d.delimiter != 0 {
+		cr.Comma = d.delimiter
+	}
+
+	header, err := cr.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*column, len(header))
+
+	for i, v := range header {
+		columns[i] = d.column(v)
+	}
+
+	for d.rows < d.sampleSize {
+		row, err := cr.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		d.rows++
+
+		for i, c := range columns {
+			if i < len(row) {
+				c.observeString(row[i])
+			}
+		}
+	}
+
+	s := d.schema()
+
+	if d.delimiter != 0 && d.delimiter != ',' {
+		s.Settings = &Settings{Delimiter: string(d.delimiter)}
+	}
+
+	return []*Schema{s}, nil
+}
+
+// DetectJSON infers the schema of the JSON array or newline delimited JSON
+// objects read from r.
+func DetectJSON(r io.Reader, name string, options ...DetectOption) ([]*Schema, error) {
+	d := newDetector(name, options)
+	br := bufio.NewReader(r)
+	b, err := firstByte(br)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+
+	if b == '[' {
+		_, err = dec.Token()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for d.rows < d.sampleSize && dec.More() {
+		err = d.observeObject(dec)
+
+		if err != nil {
+			return nil, err
+		}
+
+		d.rows++
+	}
+
+	return []*Schema{d.schema()}, nil
+}
+
+func newDetector(name string, options []DetectOption) *detector {
+	d := &detector{
+		name:       name,
+		sampleSize: DefaultSampleSize,
+		index:      map[string]*column{},
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	return d
+}
+
+func (d *detector) column(name string) *column {
+	c, exists := d.index[name]
+
+	if !exists {
+		c = &column{
+			name:      name,
+			values:    map[string]bool{},
+			unique:    true,
+			kinds:     map[FieldType]bool{},
+			layouts:   map[FieldType][]string{},
+			boolWords: map[string]bool{},
+		}
+		d.index[name] = c
+		d.columns = append(d.columns, c)
+	}
+
+	return c
+}
+
+// observeObject decodes a single JSON object, preserving the key order.
+func (d *detector) observeObject(dec *json.Decoder) error {
+	t, err := dec.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if t != json.Delim('{') {
+		return fmt.Errorf("%w: expected object, got %v", ErrType, t)
+	}
+
+	for dec.More() {
+		t, err = dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		var v interface{}
+		err = dec.Decode(&v)
+
+		if err != nil {
+			return err
+		}
+
+		name, _ := t.(string)
+		d.column(name).observe(v)
+	}
+
+	_, err = dec.Token()
+
+	return err
+}
+
+func (c *column) observe(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		c.present++
+		c.empty++
+
+	case string:
+		c.observeString(v)
+
+	case bool:
+		c.record(strconv.FormatBool(v), Boolean)
+
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			c.record(v.String(), Integer)
+		} else {
+			c.record(v.String(), Number)
+		}
+
+	case []interface{}:
+		b, _ := json.Marshal(v)
+		c.record(string(b), Array)
+
+	default:
+		b, _ := json.Marshal(v)
+		c.record(string(b), Object)
+	}
+}
+
+func (c *column) observeString(s string) {
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		c.present++
+		c.empty++
+
+		return
+	}
+
+	if isInteger(s) && !hasLeadingZero(s) {
+		c.record(s, Integer)
+		return
+	}
+
+	if isFinite(s) && !hasLeadingZero(s) {
+		c.record(s, Number)
+		return
+	}
+
+	if isBoolWord(s) {
+		c.boolWords[strings.ToLower(s)] = true
+		c.record(s, Boolean)
+
+		return
+	}
+
+	for _, t := range []FieldType{Date, DateTime, Time} {
+		if layouts := c.matchLayouts(t, s); len(layouts) > 0 {
+			c.layouts[t] = layouts
+			c.record(s, t)
+
+			return
+		}
+	}
+
+	switch {
+	case namedFormats[UUIDFormat].MatchString(s):
+		c.record(s, UUID)
+
+	case namedFormats[EmailFormat].MatchString(s):
+		c.record(s, Email)
+
+	case strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://"):
+		c.record(s, URL)
+
+	default:
+		c.record(s, String)
+	}
+}
+
+// hasLeadingZero reports whether the numeric value s has a leading zero, e.g.
+// a zero padded code such as 007, which is kept as a string.
+func hasLeadingZero(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+
+	return len(s) > 1 && s[0] == '0' && s[1] != '.'
+}
+
+// isFinite reports whether s is a finite number, unlike NaN and Inf which
+// are accepted by strconv.ParseFloat.
+func isFinite(s string) bool {
+	v, err := strconv.ParseFloat(s, 64)
+
+	return err == nil && !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// boolWordPairs lists the boolean words detected besides those accepted by
+// strconv.ParseBool. Values are only parsed and validated as booleans
+// according to strconv.ParseBool, the words being described by the format of
+// the detected field.
+var boolWordPairs = [][2]string{{"yes", "no"}, {"y", "n"}, {"on", "off"}}
+
+func isBoolWord(s string) bool {
+	_, err := strconv.ParseBool(s)
+
+	if err == nil {
+		return true
+	}
+
+	for _, w := range boolWordPairs {
+		if strings.EqualFold(s, w[0]) || strings.EqualFold(s, w[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchLayouts returns the layouts of the given temporal type still matching
+// every value of the column including s.
+func (c *column) matchLayouts(t FieldType, s string) []string {
+	candidates, seen := c.layouts[t]
+
+	if !seen {
+		switch t {
+		case Date:
+			candidates = dateLayouts
+
+		case DateTime:
+			candidates = dateTimeLayouts
+
+		default:
+			candidates = timeLayouts
+		}
+	}
+
+	var layouts []string
+
+	for _, v := range candidates {
+		if _, err := time.Parse(v, s); err == nil {
+			layouts = append(layouts, v)
+		}
+	}
+
+	return layouts
+}
+
+func (c *column) record(s string, t FieldType) {
+	c.present++
+	c.kinds[t] = true
+
+	if c.values[s] {
+		c.unique = false
+	} else {
+		c.values[s] = true
+	}
+}
+
+// fieldType resolves the observed value kinds into a single field type.
+func (c *column) fieldType() FieldType {
+	only := func(types ...FieldType) bool {
+		n := 0
+
+		for _, t := range types {
+			if c.kinds[t] {
+				n++
+			}
+		}
+
+		return n == len(c.kinds)
+	}
+
+	switch {
+	case len(c.kinds) == 0:
+		return String
+
+	case len(c.kinds) == 1:
+		for k := range c.kinds {
+			return k
+		}
+
+	case only(Integer, Number):
+		return Number
+
+	case only(Date, DateTime):
+		return DateTime
+
+	case only(Object, Array):
+		return JSON
+	}
+
+	return String
+}
+
+func (c *column) field(rows int) *Field {
+	f := &Field{
+		Name:     c.name,
+		Type:     c.fieldType(),
+		Required: rows > 0 && c.present == rows && c.empty == 0,
+	}
+
+	switch {
+	case f.Type.IsTemporal():
+		layouts := c.layouts[f.Type]
+
+		if len(layouts) > 0 && layouts[0] != f.Type.Layout("") {
+			f.Format = &layouts[0]
+		}
+
+	case f.Type == Boolean:
+		if words := c.boolFormat(); words != "" {
+			f.Format = &words
+		}
+	}
+
+	return f
+}
+
+// boolFormat returns a pattern describing non true/false boolean values.
+func (c *column) boolFormat() string {
+	words := make([]string, 0, len(c.boolWords))
+
+	for k := range c.boolWords {
+		if k != "true" && k != "false" {
+			words = append(words, k)
+		}
+	}
+
+	if len(words) == 0 {
+		return ""
+	}
+
+	for _, v := range boolWordPairs {
+		if c.boolWords[v[0]] || c.boolWords[v[1]] {
+			return "^(?i:" + v[0] + "|" + v[1] + ")$"
+		}
+	}
+
+	return ""
+}
+
+func (d *detector) schema() *Schema {
+	s := &Schema{Name: d.name, Fields: make([]*Field, 0, len(d.columns))}
+
+	var candidates []*column
+
+	for _, c := range d.columns {
+		f := c.field(d.rows)
+		s.Fields = append(s.Fields, f)
+
+		if f.Required && c.unique && d.rows > 1 && (f.Type == Integer || f.Type == UUID || f.Type == String) {
+			candidates = append(candidates, c)
+		}
+	}
+
+	// Prefer an identifier named column amongst the unique candidates, string
+	// columns such as names being unique by chance otherwise
+	for _, c := range candidates {
+		if n := strings.ToLower(c.name); n == "id" || strings.HasSuffix(n, "_id") {
+			s.PrimaryKey = []string{c.name}
+
+			return s
+		}
+	}
+
+	for _, c := range candidates {
+		if t := c.fieldType(); t == Integer || t == UUID {
+			s.PrimaryKey = []string{c.name}
+
+			return s
+		}
+	}
+
+	return s
+}
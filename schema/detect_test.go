@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/instapi/client-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCSV(t *testing.T) {
+	const data = "code,id,name,founded,active,rating,updated\n" +
+		"A,1,Acme,03/02/2001,yes,4.5,2021-01-02T03:04:05Z\n" +
+		"A,2,Other,04/02/2001,no,3,\n" +
+		"B,3,,05/02/2001,yes,5,2021-01-02T03:04:05Z\n"
+
+	s, err := DetectCSV(strings.NewReader(data), "companies")
+
+	require.NoError(t, err)
+	require.Len(t, s, 1)
+
+	layout := "02/01/2006"
+	words := "^(?i:yes|no)$"
+
+	require.Equal(t, &Schema{
+		Name:       "companies",
+		PrimaryKey: []string{"id"},
+		Fields: []*Field{
+			{Name: "code", Type: String, Required: true},
+			{Name: "id", Type: Integer, Required: true},
+			{Name: "name", Type: String},
+			{Name: "founded", Type: Date, Format: &layout, Required: true},
+			{Name: "active", Type: Boolean, Format: &words, Required: true},
+			{Name: "rating", Type: Number, Required: true},
+			{Name: "updated", Type: DateTime},
+		},
+	}, s[0])
+
+	// Boolean words are only understood by detection
+	err = ValidateCSV(s[0], strings.NewReader(data))
+
+	var verr *ValidationError
+
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Violations, 3)
+
+	for _, v := range verr.Violations {
+		require.Equal(t, "active", v.Field)
+		require.ErrorIs(t, v, ErrType)
+	}
+}
+
+func TestDetectCSVAmbiguous(t *testing.T) {
+	const data = "name,code,score,ratio\n" +
+		"Acme,007,NaN,1.5\n" +
+		"Other,012,1,Inf\n" +
+		"Third,3,2,2\n"
+
+	s, err := DetectCSV(strings.NewReader(data), "companies")
+
+	require.NoError(t, err)
+	require.Equal(t, &Schema{
+		Name: "companies",
+		Fields: []*Field{
+			{Name: "name", Type: String, Required: true},
+			{Name: "code", Type: String, Required: true},
+			{Name: "score", Type: String, Required: true},
+			{Name: "ratio", Type: String, Required: true},
+		},
+	}, s[0])
+
+	require.NoError(t, ValidateCSV(s[0], strings.NewReader(data)))
+}
+
+func TestDetectJSON(t *testing.T) {
+	const data = `{"uuid": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "email": "a@b.co", "tags": ["a"], "n": 1}
+{"uuid": "6ba7b811-9dad-11d1-80b4-00c04fd430c8", "email": null, "tags": [], "n": 1.5, "extra": true}
+`
+
+	s, err := DetectJSON(strings.NewReader(data), "things", SampleSize(10))
+
+	require.NoError(t, err)
+	require.Equal(t, &Schema{
+		Name:       "things",
+		PrimaryKey: []string{"uuid"},
+		Fields: []*Field{
+			{Name: "uuid", Type: UUID, Required: true},
+			{Name: "email", Type: Email},
+			{Name: "tags", Type: Array, Required: true},
+			{Name: "n", Type: Number, Required: true},
+			{Name: "extra", Type: Boolean},
+		},
+	}, s[0])
+}
+
+func TestDetectContentType(t *testing.T) {
+	s, err := Detect(strings.NewReader("id\tname\n1\tAcme\n"), "companies", types.TSV)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name"}, []string{s[0].Fields[0].Name, s[0].Fields[1].Name})
+
+	_, err = Detect(strings.NewReader(""), "companies", types.XLSX)
+
+	require.ErrorIs(t, err, ErrUnsupportedContentType)
+	require.NotErrorIs(t, err, ErrUnsupportedFormat)
+}
@@ -0,0 +1,341 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change kinds reported by Diff.
+const (
+	FieldAdded        = "field added"
+	FieldRemoved      = "field removed"
+	FieldRenamed      = "field renamed"
+	FieldRetyped      = "field retyped"
+	FieldRequired     = "field required changed"
+	FieldFormat       = "field format changed"
+	FieldMapping      = "field mapping changed"
+	PrimaryKeyChanged = "primary key changed"
+	IndexAdded        = "index added"
+	IndexRemoved      = "index removed"
+	SettingsChanged   = "settings changed"
+)
+
+// Change represents a single difference between two schema versions.
+type Change struct {
+	Kind     string      `json:"kind"`
+	Field    string      `json:"field,omitempty"`
+	From     interface{} `json:"from,omitempty"`
+	To       interface{} `json:"to,omitempty"`
+	Breaking bool        `json:"breaking"`
+}
+
+func (c *Change) String() string {
+	var sb strings.Builder
+
+	if c.Breaking {
+		sb.WriteString("! ")
+	} else {
+		sb.WriteString("  ")
+	}
+
+	sb.WriteString(c.Kind)
+
+	if c.Field != "" {
+		sb.WriteString(" ")
+		sb.WriteString(c.Field)
+	}
+
+	if c.From != nil || c.To != nil {
+		fmt.Fprintf(&sb, ": %v -> %v", display(c.From), display(c.To))
+	}
+
+	return sb.String()
+}
+
+// Plan represents a migration plan between two schema versions. Backfill
+// holds values set on existing records whose field is missing once the
+// schema has been updated.
+type Plan struct {
+	From          *Schema                `json:"from"`
+	To            *Schema                `json:"to"`
+	Changes       []*Change              `json:"changes"`
+	Backfill      map[string]interface{} `json:"backfill,omitempty"`
+	AllowBreaking bool                   `json:"allowBreaking,omitempty"`
+}
+
+// Empty reports whether the plan has no changes.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0 && len(p.Backfill) == 0
+}
+
+// Breaking reports whether the plan contains breaking changes.
+func (p *Plan) Breaking() bool {
+	for _, c := range p.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Renames returns the renamed fields, keyed by their previous name.
+func (p *Plan) Renames() map[string]string {
+	renames := map[string]string{}
+
+	for _, c := range p.Changes {
+		from, _ := c.From.(string)
+		to, _ := c.To.(string)
+
+		if c.Kind == FieldRenamed && from != "" && to != "" {
+			renames[from] = to
+		}
+	}
+
+	return renames
+}
+
+func (p *Plan) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "schema %s: %d change(s)", p.To.Name, len(p.Changes))
+
+	for _, c := range p.Changes {
+		sb.WriteString("\n")
+		sb.WriteString(c.String())
+	}
+
+	keys := make([]string, 0, len(p.Backfill))
+
+	for k := range p.Backfill {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "\n  backfill %s = %v", k, display(p.Backfill[k]))
+	}
+
+	return sb.String()
+}
+
+// DiffOption represents a diff option.
+type DiffOption func(*differ)
+
+// Renamed declares that the field previously named from is now named to.
+// Fields are otherwise only considered renamed when they share a mapping.
+func Renamed(from, to string) DiffOption {
+	return func(d *differ) {
+		d.renames[from] = to
+	}
+}
+
+type differ struct {
+	renames map[string]string
+}
+
+// Diff compares two versions of a schema and returns the migration plan,
+// classifying each change as safe or breaking.
+func Diff(from, to *Schema, options ...DiffOption) *Plan {
+	d := &differ{renames: map[string]string{}}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	p := &Plan{From: from, To: to}
+
+	var removed []*Field
+
+	matched := map[string]bool{}
+
+	for _, f := range from.Fields {
+		name := f.Name
+
+		if v, exists := d.renames[name]; exists {
+			name = v
+		}
+
+		n := to.Field(name)
+
+		if n == nil {
+			removed = append(removed, f)
+			continue
+		}
+
+		matched[n.Name] = true
+
+		if n.Name != f.Name {
+			p.Changes = append(p.Changes, &Change{Kind: FieldRenamed, Field: n.Name, From: f.Name, To: n.Name, Breaking: true})
+		}
+
+		p.Changes = append(p.Changes, diffField(f, n)...)
+	}
+
+	var added []*Field
+
+	for _, f := range to.Fields {
+		if !matched[f.Name] {
+			added = append(added, f)
+		}
+	}
+
+	// Pair removed and added fields sharing a mapping as renames
+	for i := 0; i < len(removed); i++ {
+		f := removed[i]
+
+		if f.Mapping == "" {
+			continue
+		}
+
+		for j, n := range added {
+			if n.Mapping != f.Mapping {
+				continue
+			}
+
+			p.Changes = append(p.Changes, &Change{Kind: FieldRenamed, Field: n.Name, From: f.Name, To: n.Name, Breaking: true})
+			p.Changes = append(p.Changes, diffField(f, n)...)
+			removed = append(removed[:i], removed[i+1:]...)
+			added = append(added[:j], added[j+1:]...)
+			i--
+
+			break
+		}
+	}
+
+	for _, f := range removed {
+		p.Changes = append(p.Changes, &Change{Kind: FieldRemoved, Field: f.Name, Breaking: true})
+	}
+
+	for _, f := range added {
+		p.Changes = append(p.Changes, &Change{Kind: FieldAdded, Field: f.Name, To: f.Type, Breaking: f.Required})
+	}
+
+	if !equalStrings(from.PrimaryKey, to.PrimaryKey) {
+		p.Changes = append(p.Changes, &Change{Kind: PrimaryKeyChanged, From: from.PrimaryKey, To: to.PrimaryKey, Breaking: true})
+	}
+
+	for _, v := range difference(to.Indexed, from.Indexed) {
+		p.Changes = append(p.Changes, &Change{Kind: IndexAdded, Field: v})
+	}
+
+	for _, v := range difference(from.Indexed, to.Indexed) {
+		p.Changes = append(p.Changes, &Change{Kind: IndexRemoved, Field: v})
+	}
+
+	p.Changes = append(p.Changes, diffSettings(from.Settings, to.Settings)...)
+
+	return p
+}
+
+func diffField(f, n *Field) []*Change {
+	var changes []*Change
+
+	if f.Type != n.Type {
+		changes = append(changes, &Change{Kind: FieldRetyped, Field: n.Name, From: f.Type, To: n.Type, Breaking: !widens(f.Type, n.Type)})
+	}
+
+	if f.Required != n.Required {
+		changes = append(changes, &Change{Kind: FieldRequired, Field: n.Name, From: f.Required, To: n.Required, Breaking: n.Required})
+	}
+
+	if f.format() != n.format() {
+		changes = append(changes, &Change{Kind: FieldFormat, Field: n.Name, From: f.format(), To: n.format(), Breaking: n.format() != ""})
+	}
+
+	if f.Mapping != n.Mapping {
+		changes = append(changes, &Change{Kind: FieldMapping, Field: n.Name, From: f.Mapping, To: n.Mapping})
+	}
+
+	return changes
+}
+
+func diffSettings(from, to *Settings) []*Change {
+	if from == nil {
+		from = &Settings{}
+	}
+
+	if to == nil {
+		to = &Settings{}
+	}
+
+	var changes []*Change
+
+	a, b := reflect.ValueOf(*from), reflect.ValueOf(*to)
+
+	for i := 0; i < a.NumField(); i++ {
+		x, y := a.Field(i).String(), b.Field(i).String()
+
+		if x != y {
+			name := strings.Split(a.Type().Field(i).Tag.Get("json"), ",")[0]
+			changes = append(changes, &Change{Kind: SettingsChanged, Field: name, From: x, To: y})
+		}
+	}
+
+	return changes
+}
+
+// widens reports whether every value of type from is representable as type to.
+func widens(from, to FieldType) bool {
+	switch to {
+	case String, Text:
+		return true
+
+	case Number, Decimal:
+		return from.IsNumeric()
+
+	case DateTime, Timestamp:
+		return from.IsTemporal() && from != Time
+	}
+
+	return false
+}
+
+func display(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return `""`
+		}
+
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	}
+
+	return v
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func difference(a, b []string) []string {
+	m := make(map[string]bool, len(b))
+
+	for _, v := range b {
+		m[v] = true
+	}
+
+	var dst []string
+
+	for _, v := range a {
+		if !m[v] {
+			dst = append(dst, v)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	layout := "2006-01-02"
+	from := &Schema{
+		Name:       "companies",
+		PrimaryKey: []string{"id"},
+		Indexed:    []string{"name"},
+		Fields: []*Field{
+			{Name: "id", Type: "integer", Required: true},
+			{Name: "name", Type: "string", Required: true},
+			{Name: "staff", Type: "integer"},
+			{Name: "web", Type: "string"},
+			{Name: "legacy", Type: "string"},
+			{Name: "zip", Type: "string", Mapping: "Zip Code"},
+		},
+	}
+	to := &Schema{
+		Name:       "companies",
+		PrimaryKey: []string{"id"},
+		Indexed:    []string{"website"},
+		Settings:   &Settings{Delimiter: ";"},
+		Fields: []*Field{
+			{Name: "id", Type: "integer", Required: true},
+			{Name: "name", Type: "string"},
+			{Name: "staff", Type: "number"},
+			{Name: "website", Type: "string", Required: true},
+			{Name: "postcode", Type: "string", Mapping: "Zip Code"},
+			{Name: "founded", Type: "date", Format: &layout},
+		},
+	}
+
+	p := Diff(from, to, Renamed("web", "website"))
+
+	require.Equal(t, []*Change{
+		{Kind: FieldRequired, Field: "name", From: true, To: false},
+		{Kind: FieldRetyped, Field: "staff", From: Integer, To: Number},
+		{Kind: FieldRenamed, Field: "website", From: "web", To: "website", Breaking: true},
+		{Kind: FieldRequired, Field: "website", From: false, To: true, Breaking: true},
+		{Kind: FieldRenamed, Field: "postcode", From: "zip", To: "postcode", Breaking: true},
+		{Kind: FieldRemoved, Field: "legacy", Breaking: true},
+		{Kind: FieldAdded, Field: "founded", To: Date},
+		{Kind: IndexAdded, Field: "website"},
+		{Kind: IndexRemoved, Field: "name"},
+		{Kind: SettingsChanged, Field: "delimiter", From: "", To: ";"},
+	}, p.Changes)
+	require.True(t, p.Breaking())
+	require.True(t, Diff(to, to).Empty())
+}
@@ -0,0 +1,371 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field type errors. ErrUnknownType wraps ErrType.
+var (
+	ErrUnknownType = fmt.Errorf("%w: unknown field type", ErrType)
+)
+
+// FieldType represents a schema field type.
+type FieldType string
+
+// Supported field types.
+const (
+	String    FieldType = "string"
+	Text      FieldType = "text"
+	Email     FieldType = "email"
+	URL       FieldType = "url"
+	UUID      FieldType = "uuid"
+	Integer   FieldType = "integer"
+	Number    FieldType = "number"
+	Decimal   FieldType = "decimal"
+	Boolean   FieldType = "boolean"
+	Date      FieldType = "date"
+	DateTime  FieldType = "datetime"
+	Time      FieldType = "time"
+	Timestamp FieldType = "timestamp"
+	JSON      FieldType = "json"
+	Object    FieldType = "object"
+	Array     FieldType = "array"
+)
+
+// FieldTypes lists every supported field type.
+var FieldTypes = []FieldType{
+	String, Text, Email, URL, UUID,
+	Integer, Number, Decimal, Boolean,
+	Date, DateTime, Time, Timestamp,
+	JSON, Object, Array,
+}
+
+var typeAliases = map[string]FieldType{
+	"int":   Integer,
+	"float": Number,
+	"bool":  Boolean,
+}
+
+// Default date and time layouts used when a field has no format.
+const (
+	DateLayout     = "2006-01-02"
+	DateTimeLayout = time.RFC3339
+	TimeLayout     = "15:04:05"
+)
+
+// Named formats for text fields. Any other format of a non-temporal field is
+// interpreted as a regular expression.
+const (
+	EmailFormat = "email"
+	URIFormat   = "uri"
+	UUIDFormat  = "uuid"
+	IPv4Format  = "ipv4"
+)
+
+var namedFormats = map[string]*regexp.Regexp{
+	EmailFormat: regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`),
+	URIFormat:   regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:[^\s]*$`),
+	UUIDFormat:  regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+	IPv4Format:  regexp.MustCompile(`^((25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(25[0-5]|2[0-4]\d|1?\d?\d)$`),
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	goTypes        = map[FieldType]reflect.Type{
+		Integer: reflect.TypeOf(int64(0)),
+		Number:  reflect.TypeOf(float64(0)),
+		Decimal: reflect.TypeOf(float64(0)),
+		Boolean: reflect.TypeOf(false),
+		JSON:    rawMessageType,
+		Object:  reflect.TypeOf(map[string]interface{}{}),
+		Array:   reflect.TypeOf([]interface{}{}),
+	}
+)
+
+// ParseFieldType parses the given field type name, accepting the int, float
+// and bool aliases regardless of case.
+func ParseFieldType(s string) (FieldType, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if t, exists := typeAliases[s]; exists {
+		return t, nil
+	}
+
+	t := FieldType(s)
+
+	if !t.Valid() {
+		return "", fmt.Errorf("%w: %q (expected one of %s)", ErrUnknownType, s, joinTypes())
+	}
+
+	return t, nil
+}
+
+// FieldTypeOf returns the field type for the given Go type.
+func FieldTypeOf(t reflect.Type) FieldType {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return DateTime
+
+	case rawMessageType:
+		return JSON
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return String
+
+	case reflect.Bool:
+		return Boolean
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Integer
+
+	case reflect.Float32, reflect.Float64:
+		return Number
+
+	case reflect.Slice, reflect.Array:
+		return Array
+	}
+
+	return Object
+}
+
+// Valid reports whether the field type is supported.
+func (t FieldType) Valid() bool {
+	for _, v := range FieldTypes {
+		if t == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsText reports whether the field type holds text values.
+func (t FieldType) IsText() bool {
+	switch t {
+	case String, Text, Email, URL, UUID:
+		return true
+	}
+
+	return false
+}
+
+// IsNumeric reports whether the field type holds numeric values.
+func (t FieldType) IsNumeric() bool {
+	switch t {
+	case Integer, Number, Decimal:
+		return true
+	}
+
+	return false
+}
+
+// IsTemporal reports whether the field type holds date or time values.
+func (t FieldType) IsTemporal() bool {
+	switch t {
+	case Date, DateTime, Time, Timestamp:
+		return true
+	}
+
+	return false
+}
+
+// GoType returns the Go type used to represent values of the field type.
+func (t FieldType) GoType() reflect.Type {
+	switch {
+	case t.IsText():
+		return reflect.TypeOf("")
+
+	case t.IsTemporal():
+		return timeType
+	}
+
+	if v, exists := goTypes[t]; exists {
+		return v
+	}
+
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
+
+// Layout returns the time layout for temporal field types, using the given
+// format when not empty.
+func (t FieldType) Layout(format string) string {
+	if format != "" {
+		return format
+	}
+
+	switch t {
+	case Date:
+		return DateLayout
+
+	case Time:
+		return TimeLayout
+	}
+
+	return DateTimeLayout
+}
+
+// Parse parses a string value according to the field type and format. Text
+// values are returned as is, numeric, boolean and temporal values as int64,
+// float64, bool and time.Time, and JSON values as decoded JSON.
+func (t FieldType) Parse(s, format string) (interface{}, error) {
+	switch {
+	case t.IsText():
+		return s, nil
+
+	case t.IsTemporal():
+		layout := t.Layout(format)
+		v, err := time.Parse(layout, s)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q does not match layout %s", ErrFormat, s, layout)
+		}
+
+		return v, nil
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+
+	switch t {
+	case Integer:
+		v, err = strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+
+	case Number, Decimal:
+		v, err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+
+	case Boolean:
+		v, err = strconv.ParseBool(strings.TrimSpace(s))
+
+	case JSON:
+		if !json.Valid([]byte(s)) {
+			return nil, fmt.Errorf("%w: expected %s, got %q", ErrType, t, s)
+		}
+
+		return json.RawMessage(s), nil
+
+	case Object:
+		var m map[string]interface{}
+		err = json.Unmarshal([]byte(s), &m)
+		v = m
+
+	case Array:
+		var a []interface{}
+		err = json.Unmarshal([]byte(s), &a)
+		v = a
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, string(t))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected %s, got %q", ErrType, t, s)
+	}
+
+	return v, nil
+}
+
+func (t FieldType) String() string {
+	return string(t)
+}
+
+// MarshalJSON implements the json.Marshaler interface, rejecting unknown
+// types.
+func (t FieldType) MarshalJSON() ([]byte, error) {
+	if !t.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, string(t))
+	}
+
+	return json.Marshal(string(t))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Aliases are
+// resolved, but unknown types, e.g. added to the API since, are kept as is
+// rather than rejected, so that they only fail when written back.
+func (t *FieldType) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+
+	if err != nil {
+		return err
+	}
+
+	*t = fieldType(s)
+
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, rejecting
+// unknown types.
+func (t FieldType) MarshalText() ([]byte, error) {
+	if !t.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, string(t))
+	}
+
+	return []byte(t), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, decoding
+// any type as with UnmarshalJSON.
+func (t *FieldType) UnmarshalText(b []byte) error {
+	*t = fieldType(string(b))
+
+	return nil
+}
+
+// fieldType parses the given field type name, keeping unknown types as is.
+func fieldType(s string) FieldType {
+	if t, err := ParseFieldType(s); err == nil {
+		return t
+	}
+
+	return FieldType(s)
+}
+
+// FormatPattern returns the regular expression for the given named format or
+// pattern.
+func FormatPattern(format string) (*regexp.Regexp, error) {
+	if re, exists := namedFormats[format]; exists {
+		return re, nil
+	}
+
+	return regexp.Compile(format)
+}
+
+// Parse parses a string value according to the field type and format.
+func (f *Field) Parse(s string) (interface{}, error) {
+	return f.Type.Parse(s, f.format())
+}
+
+func (f *Field) format() string {
+	if f.Format == nil {
+		return ""
+	}
+
+	return *f.Format
+}
+
+func joinTypes() string {
+	s := make([]string, len(FieldTypes))
+
+	for i, v := range FieldTypes {
+		s[i] = string(v)
+	}
+
+	return strings.Join(s, ", ")
+}
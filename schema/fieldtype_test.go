@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldType(t *testing.T) {
+	for s, expected := range map[string]FieldType{
+		"integer": Integer,
+		"INT":     Integer,
+		" float ": Number,
+		"bool":    Boolean,
+		"Date":    Date,
+	} {
+		v, err := ParseFieldType(s)
+
+		require.NoError(t, err)
+		require.Equal(t, expected, v)
+	}
+
+	_, err := ParseFieldType("interger")
+
+	require.ErrorIs(t, err, ErrUnknownType)
+}
+
+func TestFieldTypeJSON(t *testing.T) {
+	var f Field
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"id","type":"Integer"}`), &f))
+	require.Equal(t, Integer, f.Type)
+
+	b, err := json.Marshal(&f)
+
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"id","type":"integer"}`, string(b))
+
+	// Unknown types are kept, so that newer API types do not break decoding,
+	// but are rejected when encoded
+	for _, v := range []string{"geometry", ""} {
+		require.NoError(t, json.Unmarshal([]byte(`{"name":"id","type":"`+v+`"}`), &f))
+		require.Equal(t, FieldType(v), f.Type)
+
+		_, err = json.Marshal(&f)
+
+		require.ErrorIs(t, err, ErrType)
+		require.ErrorIs(t, err, ErrUnknownType)
+		require.Contains(t, err.Error(), `unknown field type: "`+v+`"`)
+
+		_, err = FieldType(v).MarshalText()
+
+		require.ErrorIs(t, err, ErrUnknownType)
+	}
+
+	s := &Schema{Name: "test", Fields: []*Field{{Name: "id", Type: Integer}, {Name: "geo", Type: "geometry"}}}
+
+	require.ErrorIs(t, s.CheckTypes(), ErrUnknownType)
+
+	_, err = NewValidator(s)
+
+	require.ErrorIs(t, err, ErrUnknownType)
+	require.ErrorIs(t, Validate(s, map[string]interface{}{"id": 1}), ErrUnknownType)
+}
+
+func TestFieldTypeOf(t *testing.T) {
+	require.Equal(t, Integer, FieldTypeOf(reflect.TypeOf(uint8(0))))
+	require.Equal(t, DateTime, FieldTypeOf(reflect.TypeOf(&time.Time{})))
+	require.Equal(t, JSON, FieldTypeOf(reflect.TypeOf(json.RawMessage{})))
+	require.Equal(t, Object, FieldTypeOf(reflect.TypeOf(struct{}{})))
+}
+
+func TestFieldTypeParse(t *testing.T) {
+	v, err := Integer.Parse("42", "")
+
+	require.NoError(t, err)
+	require.Equal(t, int64(42), v)
+
+	v, err = Date.Parse("03/02/2001", "02/01/2006")
+
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC), v)
+
+	_, err = Date.Parse("2001-02-30", "")
+
+	require.ErrorIs(t, err, ErrFormat)
+
+	_, err = Boolean.Parse("maybe", "")
+
+	require.ErrorIs(t, err, ErrType)
+}
@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File format constants.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// File related errors.
+var (
+	ErrUnsupportedFormat = errors.New("unsupported schema file format")
+)
+
+// ReadFile reads the schemas defined in the given JSON or YAML file. A file
+// may contain either a single schema or a list of schemas.
+func ReadFile(filename string) ([]*Schema, error) {
+	format, err := formatFromExt(filepath.Ext(filename))
+
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filename) // nolint: gosec
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close() // nolint: errcheck
+
+	s, err := Read(f, format)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return s, nil
+}
+
+// ReadDir reads the schemas defined in the JSON and YAML files of the given
+// directory, in lexical file order.
+func ReadDir(dir string) ([]*Schema, error) {
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var s []*Schema
+
+	for _, v := range entries {
+		if v.IsDir() {
+			continue
+		}
+
+		if _, err := formatFromExt(filepath.Ext(v.Name())); err != nil {
+			continue
+		}
+
+		n, err := ReadFile(filepath.Join(dir, v.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, n...)
+	}
+
+	return s, nil
+}
+
+// Read reads the schemas from the given reader in the given format.
+func Read(r io.Reader, format string) ([]*Schema, error) {
+	b, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSON:
+	case FormatYAML:
+		b, err = yamlToJSON(b)
+
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	b = bytes.TrimSpace(b)
+
+	if len(b) > 0 && b[0] == '[' {
+		var s []*Schema
+		err = json.Unmarshal(b, &s)
+
+		return s, err
+	}
+
+	var s *Schema
+	err = json.Unmarshal(b, &s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Schema{s}, nil
+}
+
+// Write writes the given schemas to the writer in the given format.
+func Write(w io.Writer, format string, s ...*Schema) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(s)
+
+	case FormatYAML:
+		b, err := json.Marshal(s)
+
+		if err != nil {
+			return err
+		}
+
+		var v interface{}
+		err = yaml.Unmarshal(b, &v)
+
+		if err != nil {
+			return err
+		}
+
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+
+		err = enc.Encode(v)
+
+		if err != nil {
+			return err
+		}
+
+		return enc.Close()
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+func formatFromExt(ext string) (string, error) {
+	switch strings.ToLower(ext) {
+	case ".json":
+		return FormatJSON, nil
+
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, ext)
+}
+
+func yamlToJSON(b []byte) ([]byte, error) {
+	var v interface{}
+	err := yaml.Unmarshal(b, &v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSchemas() []*Schema {
+	format := "email"
+
+	return []*Schema{
+		{
+			Name:       "companies",
+			PrimaryKey: []string{"id"},
+			Settings:   &Settings{Delimiter: ";"},
+			Fields: []*Field{
+				{Name: "id", Type: Integer, Required: true},
+				{Name: "contact", Mapping: "Contact", Type: String, Format: &format},
+			},
+		},
+		{Name: "people", Fields: []*Field{{Name: "born", Type: Date}}},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			require.NoError(t, Write(&buf, format, testSchemas()...))
+
+			s, err := Read(&buf, format)
+
+			require.NoError(t, err)
+			require.Equal(t, testSchemas(), s)
+		})
+	}
+
+	require.ErrorIs(t, Write(&bytes.Buffer{}, "toml", testSchemas()...), ErrUnsupportedFormat)
+
+	_, err := Read(strings.NewReader(""), "toml")
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestRead(t *testing.T) {
+	s, err := Read(strings.NewReader(`
+name: companies
+fields:
+  - name: id
+    type: INT
+    required: true
+`), FormatYAML)
+
+	require.NoError(t, err)
+	require.Equal(t, []*Schema{{Name: "companies", Fields: []*Field{{Name: "id", Type: Integer, Required: true}}}}, s)
+
+	s, err = Read(strings.NewReader(` [{"name": "a", "fields": []}, {"name": "b", "fields": []}]`), FormatJSON)
+
+	require.NoError(t, err)
+	require.Len(t, s, 2)
+	require.Equal(t, "b", s[1].Name)
+
+	_, err = Read(strings.NewReader("name: [a"), FormatYAML)
+	require.Error(t, err)
+}
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.yml":       "name: b\nfields: []\n",
+		"a.json":      `[{"name": "a1", "fields": []}, {"name": "a2", "fields": []}]`,
+		"c.YAML":      "name: c\nfields: []\n",
+		"readme.md":   "skipped",
+		"nested/d.js": "skipped",
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0700))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+	}
+
+	s, err := ReadDir(dir)
+
+	require.NoError(t, err)
+
+	names := make([]string, len(s))
+
+	for i, v := range s {
+		names[i] = v.Name
+	}
+
+	require.Equal(t, []string{"a1", "a2", "b", "c"}, names)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "e.json"), []byte("{"), 0600))
+
+	_, err = ReadDir(dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "e.json")
+
+	_, err = ReadFile(filepath.Join(dir, "readme.md"))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = ReadFile(filepath.Join(dir, "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,357 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of exported schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSON Schema errors.
+var (
+	ErrNotObjectSchema = errors.New("JSON Schema is not an object schema")
+)
+
+// JSONSchema represents the subset of a JSON Schema used to describe
+// schemas. Instapi specific properties are carried by x-instapi extensions.
+type JSONSchema struct {
+	Schema      string      `json:"$schema,omitempty"`
+	ID          string      `json:"$id,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Type        interface{} `json:"type,omitempty"`
+	Format      string      `json:"format,omitempty"`
+	Pattern     string      `json:"pattern,omitempty"`
+	Nullable    bool        `json:"nullable,omitempty"`
+	Properties  Properties  `json:"properties,omitempty"`
+	Required    []string    `json:"required,omitempty"`
+	Items       *JSONSchema `json:"items,omitempty"`
+
+	InstapiType       FieldType `json:"x-instapi-type,omitempty"`
+	InstapiFormat     string    `json:"x-instapi-format,omitempty"`
+	InstapiMapping    string    `json:"x-instapi-mapping,omitempty"`
+	InstapiPrimaryKey []string  `json:"x-instapi-primaryKey,omitempty"`
+	InstapiIndexed    []string  `json:"x-instapi-indexed,omitempty"`
+}
+
+// Property represents a named JSON Schema property.
+type Property struct {
+	Name   string
+	Schema *JSONSchema
+}
+
+// Properties represents JSON Schema properties, preserving their order.
+type Properties []*Property
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, v := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(v.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := json.Marshal(v.Schema)
+
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(b)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Properties) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	_, err := dec.Token()
+
+	if err != nil {
+		return err
+	}
+
+	*p = nil
+
+	for dec.More() {
+		t, err := dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		name, _ := t.(string)
+		v := &Property{Name: name}
+		err = dec.Decode(&v.Schema)
+
+		if err != nil {
+			return err
+		}
+
+		*p = append(*p, v)
+	}
+
+	return nil
+}
+
+// ToJSONSchema converts the schema into a JSON Schema (draft 2020-12) object
+// schema. Optional fields accept null.
+func ToJSONSchema(s *Schema) *JSONSchema {
+	js := objectSchema(s, false)
+	js.Schema = JSONSchemaDraft
+
+	return js
+}
+
+// OpenAPIComponents represents the OpenAPI 3 components object.
+type OpenAPIComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas"`
+}
+
+// ToOpenAPI converts the given schemas into OpenAPI 3.0 component schemas
+// keyed by schema name. Optional fields are marked nullable.
+func ToOpenAPI(s ...*Schema) *OpenAPIComponents {
+	c := &OpenAPIComponents{Schemas: make(map[string]*JSONSchema, len(s))}
+
+	for _, v := range s {
+		c.Schemas[v.Name] = objectSchema(v, true)
+	}
+
+	return c
+}
+
+func objectSchema(s *Schema, openAPI bool) *JSONSchema {
+	js := &JSONSchema{
+		Title:             s.Name,
+		Type:              "object",
+		InstapiPrimaryKey: s.PrimaryKey,
+		InstapiIndexed:    s.Indexed,
+	}
+
+	for _, f := range s.Fields {
+		p := propertySchema(f)
+
+		if f.Required {
+			js.Required = append(js.Required, f.Name)
+		} else if openAPI {
+			p.Nullable = true
+		} else if p.Type != nil {
+			p.Type = []interface{}{p.Type, "null"}
+		}
+
+		js.Properties = append(js.Properties, &Property{Name: f.Name, Schema: p})
+	}
+
+	return js
+}
+
+func propertySchema(f *Field) *JSONSchema {
+	p := &JSONSchema{InstapiType: f.Type, InstapiMapping: f.Mapping}
+	format := f.format()
+
+	switch {
+	case f.Type.IsText():
+		p.Type = "string"
+
+		switch f.Type {
+		case Email:
+			p.Format = "email"
+
+		case URL:
+			p.Format = "uri"
+
+		case UUID:
+			p.Format = "uuid"
+		}
+
+		if format != "" {
+			if _, exists := namedFormats[format]; exists {
+				p.Format = format
+			} else {
+				p.Pattern = format
+			}
+		}
+
+	case f.Type.IsTemporal():
+		p.Type = "string"
+
+		if format != "" {
+			p.InstapiFormat = format
+			break
+		}
+
+		switch f.Type {
+		case Date:
+			p.Format = "date"
+
+		case Time:
+			p.Format = "time"
+
+		default:
+			p.Format = "date-time"
+		}
+
+	case f.Type == Integer:
+		p.Type = "integer"
+
+	case f.Type.IsNumeric():
+		p.Type = "number"
+
+	case f.Type == Boolean:
+		p.Type = "boolean"
+
+	case f.Type == Object:
+		p.Type = "object"
+
+	case f.Type == Array:
+		p.Type = "array"
+	}
+
+	return p
+}
+
+// FromJSONSchema converts a JSON Schema object schema into a schema. The
+// schema name defaults to the JSON Schema title.
+func FromJSONSchema(js *JSONSchema, name string) (*Schema, error) {
+	if t := jsonType(js.Type); t != "object" && t != "" {
+		return nil, fmt.Errorf("%w: type %s", ErrNotObjectSchema, t)
+	}
+
+	if name == "" {
+		name = js.Title
+	}
+
+	s := &Schema{
+		Name:       name,
+		PrimaryKey: js.InstapiPrimaryKey,
+		Indexed:    js.InstapiIndexed,
+		Fields:     make([]*Field, 0, len(js.Properties)),
+	}
+
+	required := make(map[string]bool, len(js.Required))
+
+	for _, v := range js.Required {
+		required[v] = true
+	}
+
+	for _, v := range js.Properties {
+		f, err := fieldFromJSONSchema(v.Name, v.Schema)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.Required = required[v.Name]
+		s.Fields = append(s.Fields, f)
+	}
+
+	return s, nil
+}
+
+func fieldFromJSONSchema(name string, p *JSONSchema) (*Field, error) {
+	f := &Field{Name: name, Mapping: p.InstapiMapping}
+
+	if p.InstapiType != "" {
+		f.Type = p.InstapiType
+	} else {
+		t := jsonType(p.Type)
+
+		switch t {
+		case "string":
+			f.Type = stringType(p.Format)
+
+		case "integer":
+			f.Type = Integer
+
+		case "number":
+			f.Type = Number
+
+		case "boolean":
+			f.Type = Boolean
+
+		case "object":
+			f.Type = Object
+
+		case "array":
+			f.Type = Array
+
+		case "":
+			f.Type = JSON
+
+		default:
+			return nil, fmt.Errorf("field %s: %w: %q", name, ErrUnknownType, t)
+		}
+	}
+
+	switch {
+	case p.InstapiFormat != "":
+		f.Format = &p.InstapiFormat
+
+	case p.Pattern != "":
+		f.Format = &p.Pattern
+
+	case p.Format != "" && f.Type.IsText() && namedFormats[p.Format] != nil && stringType(p.Format) == String:
+		f.Format = &p.Format
+	}
+
+	return f, nil
+}
+
+func stringType(format string) FieldType {
+	switch format {
+	case "date":
+		return Date
+
+	case "date-time":
+		return DateTime
+
+	case "time":
+		return Time
+
+	case "email":
+		return Email
+
+	case "uri", "url":
+		return URL
+
+	case "uuid":
+		return UUID
+	}
+
+	return String
+}
+
+// jsonType returns the non-null JSON type of a type keyword, which is either
+// a string or a list of strings.
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+
+	case []interface{}:
+		for _, x := range v {
+			if s, _ := x.(string); s != "null" {
+				return s
+			}
+		}
+	}
+
+	return ""
+}
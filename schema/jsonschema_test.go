@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func exportSchema() *Schema {
+	code := "^[A-Z]+$"
+	layout := "02/01/2006"
+
+	return &Schema{
+		Name:       "companies",
+		PrimaryKey: []string{"id"},
+		Indexed:    []string{"name"},
+		Fields: []*Field{
+			{Name: "id", Type: Integer, Required: true},
+			{Name: "name", Type: String, Required: true},
+			{Name: "code", Type: String, Format: &code},
+			{Name: "email", Type: Email},
+			{Name: "founded", Type: Date, Format: &layout},
+			{Name: "updated", Type: DateTime},
+			{Name: "meta", Type: JSON},
+		},
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := exportSchema()
+	b, err := json.Marshal(ToJSONSchema(s))
+
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "companies",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "x-instapi-type": "integer"},
+			"name": {"type": "string", "x-instapi-type": "string"},
+			"code": {"type": ["string", "null"], "pattern": "^[A-Z]+$", "x-instapi-type": "string"},
+			"email": {"type": ["string", "null"], "format": "email", "x-instapi-type": "email"},
+			"founded": {"type": ["string", "null"], "x-instapi-type": "date", "x-instapi-format": "02/01/2006"},
+			"updated": {"type": ["string", "null"], "format": "date-time", "x-instapi-type": "datetime"},
+			"meta": {"x-instapi-type": "json"}
+		},
+		"required": ["id", "name"],
+		"x-instapi-primaryKey": ["id"],
+		"x-instapi-indexed": ["name"]
+	}`, string(b))
+
+	var js JSONSchema
+
+	require.NoError(t, json.Unmarshal(b, &js))
+
+	imported, err := FromJSONSchema(&js, "")
+
+	require.NoError(t, err)
+	require.Equal(t, s, imported)
+}
+
+func TestFromJSONSchema(t *testing.T) {
+	var js JSONSchema
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "people",
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "format": "email"},
+			"age": {"type": ["integer", "null"]},
+			"born": {"type": "string", "format": "date"},
+			"ip": {"type": "string", "format": "ipv4"}
+		},
+		"required": ["email"]
+	}`), &js))
+
+	s, err := FromJSONSchema(&js, "")
+
+	require.NoError(t, err)
+
+	ipv4 := IPv4Format
+
+	require.Equal(t, &Schema{
+		Name: "people",
+		Fields: []*Field{
+			{Name: "email", Type: Email, Required: true},
+			{Name: "age", Type: Integer},
+			{Name: "born", Type: Date},
+			{Name: "ip", Type: String, Format: &ipv4},
+		},
+	}, s)
+}
+
+func TestDDL(t *testing.T) {
+	ddl, err := DDL(exportSchema(), Postgres)
+
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "companies" (
+  "id" BIGINT NOT NULL,
+  "name" TEXT NOT NULL,
+  "code" TEXT,
+  "email" TEXT,
+  "founded" DATE,
+  "updated" TIMESTAMPTZ,
+  "meta" JSONB,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX "companies_name_idx" ON "companies" ("name");
+`, ddl)
+
+	_, err = DDL(exportSchema(), "oracle")
+
+	require.ErrorIs(t, err, ErrUnsupportedDialect)
+}
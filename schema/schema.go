@@ -1,5 +1,7 @@
 package schema
 
+import "fmt"
+
 // Schema represents a schema.
 type Schema struct {
 	Name       string    `json:"name"`
@@ -10,6 +12,28 @@ type Schema struct {
 	Fields     []*Field  `json:"fields"`
 }
 
+// Field returns the field with the given name, or nil if it does not exist.
+func (s *Schema) Field(name string) *Field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// CheckTypes returns an error for the first field of an unknown type.
+func (s *Schema) CheckTypes() error {
+	for _, f := range s.Fields {
+		if !f.Type.Valid() {
+			return fmt.Errorf("field %s: %w: %q", f.Name, ErrUnknownType, string(f.Type))
+		}
+	}
+
+	return nil
+}
+
 // Settings represents schema settings.
 type Settings struct {
 	ExternalID     string `json:"externalId,omitempty"`
@@ -22,11 +46,11 @@ type Settings struct {
 
 // Field represents a schema field.
 type Field struct {
-	Name     string  `json:"name"`
-	Mapping  string  `json:"mapping,omitempty"`
-	Type     string  `json:"type"`
-	Format   *string `json:"format,omitempty"`
-	Required bool    `json:"required,omitempty"`
+	Name     string    `json:"name"`
+	Mapping  string    `json:"mapping,omitempty"`
+	Type     FieldType `json:"type"`
+	Format   *string   `json:"format,omitempty"`
+	Required bool      `json:"required,omitempty"`
 }
 
 // Import represents a schema import.
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/instapi/client-go/record"
+)
+
+// FromStruct builds a schema from the fields of the given struct value, or
+// pointer to one, using the `instapi` struct tag for field names and
+// options:
+//
+//	type Company struct {
+//		record.Record
+//		ID      string    `instapi:"id,pk"`
+//		Name    string    `instapi:"name,required,indexed"`
+//		Founded time.Time `instapi:"founded,type=date,format=2006-01-02"`
+//	}
+//
+// Primary key fields are always required and the field type is derived from
+// the Go type unless overridden with the type option.
+func FromStruct(v interface{}, name string) (*Schema, error) {
+	m, err := record.MappingOf(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{
+		Name:   name,
+		Fields: make([]*Field, 0, len(m.Fields)),
+	}
+
+	for _, f := range m.Fields {
+		field := &Field{
+			Name:     f.Name,
+			Type:     FieldTypeOf(f.Type),
+			Required: f.PrimaryKey,
+		}
+
+		if v, exists := f.Options["type"]; exists && v != "" {
+			field.Type, err = ParseFieldType(v)
+
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+
+		if v, exists := f.Options["format"]; exists && v != "" {
+			format := v
+			field.Format = &format
+		}
+
+		if _, exists := f.Options["required"]; exists {
+			field.Required = true
+		}
+
+		if f.PrimaryKey {
+			s.PrimaryKey = append(s.PrimaryKey, f.Name)
+		}
+
+		if _, exists := f.Options["indexed"]; exists {
+			s.Indexed = append(s.Indexed, f.Name)
+		}
+
+		s.Fields = append(s.Fields, field)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/instapi/client-go/record"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromStruct(t *testing.T) {
+	type company struct {
+		record.Record
+		ID      string     `instapi:"id,pk"`
+		Name    string     `instapi:"name,required,indexed"`
+		Staff   *int       `instapi:"staff"`
+		Rating  float64    `json:"rating"`
+		Founded *time.Time `instapi:"founded,type=date,format=2006-01-02"`
+		Tags    []string   `instapi:"tags"`
+		Skip    string     `instapi:"-"`
+	}
+
+	s, err := FromStruct(&company{}, "companies")
+
+	require.NoError(t, err)
+
+	format := "2006-01-02"
+
+	require.Equal(t, &Schema{
+		Name:       "companies",
+		PrimaryKey: []string{"id"},
+		Indexed:    []string{"name"},
+		Fields: []*Field{
+			{Name: "id", Type: "string", Required: true},
+			{Name: "name", Type: "string", Required: true},
+			{Name: "staff", Type: "integer"},
+			{Name: "rating", Type: "number"},
+			{Name: "founded", Type: "date", Format: &format},
+			{Name: "tags", Type: "array"},
+		},
+	}, s)
+}
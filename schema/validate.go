@@ -0,0 +1,636 @@
+package schema
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/instapi/client-go/record"
+)
+
+// Validation errors.
+var (
+	ErrRequired     = errors.New("required field missing")
+	ErrType         = errors.New("invalid type")
+	ErrFormat       = errors.New("invalid format")
+	ErrMissingKey   = errors.New("primary key missing")
+	ErrDuplicateKey = errors.New("duplicate primary key")
+	ErrInvalid      = errors.New("validation failed")
+)
+
+// Violation represents a single validation failure. Row is the 1-based
+// position of the record within the validated batch, excluding any CSV
+// header.
+type Violation struct {
+	Row   int
+	Field string
+	Value interface{}
+	Err   error
+}
+
+func (v *Violation) Error() string {
+	if v.Row > 0 {
+		return fmt.Sprintf("row %d: field %s: %v", v.Row, v.Field, v.Err)
+	}
+
+	return fmt.Sprintf("field %s: %v", v.Field, v.Err)
+}
+
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// ValidationError represents the violations found during validation.
+type ValidationError struct {
+	Violations []*Violation
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%v: %d violation(s)", ErrInvalid, len(e.Violations))
+
+	for _, v := range e.Violations {
+		sb.WriteString("\n")
+		sb.WriteString(v.Error())
+	}
+
+	return sb.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalid
+}
+
+// Validator validates records against a schema. A validator keeps track of
+// the primary keys seen so far, so a single validator should be used for all
+// records of a batch.
+type Validator struct {
+	schema     *Schema
+	formats    map[string]*regexp.Regexp
+	primaryKey map[string]bool
+	keys       map[string]int
+	row        int
+}
+
+// NewValidator creates a new validator for the given schema, which must not
+// hold fields of unknown types. Formats of non-temporal fields are named
+// formats or regular expressions.
+func NewValidator(s *Schema) (*Validator, error) {
+	v := &Validator{
+		schema:     s,
+		formats:    map[string]*regexp.Regexp{},
+		primaryKey: make(map[string]bool, len(s.PrimaryKey)),
+		keys:       map[string]int{},
+	}
+
+	for _, name := range s.PrimaryKey {
+		v.primaryKey[name] = true
+	}
+
+	err := s.CheckTypes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range s.Fields {
+		if f.format() == "" || f.Type.IsTemporal() {
+			continue
+		}
+
+		re, err := FormatPattern(f.format())
+
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+
+		v.formats[f.Name] = re
+	}
+
+	return v, nil
+}
+
+// Validate validates a single record against the schema, returning a
+// *ValidationError listing all violations.
+func Validate(s *Schema, rec interface{}) error {
+	v, err := NewValidator(s)
+
+	if err != nil {
+		return err
+	}
+
+	return toError(v.Validate(rec))
+}
+
+// ValidateCSV validates the CSV records read from r against the schema.
+func ValidateCSV(s *Schema, r io.Reader) error {
+	v, err := NewValidator(s)
+
+	if err != nil {
+		return err
+	}
+
+	violations, err := v.ValidateCSV(r)
+
+	if err != nil {
+		return err
+	}
+
+	return toError(violations)
+}
+
+// ValidateJSON validates the JSON array or newline delimited JSON records read
+// from r against the schema.
+func ValidateJSON(s *Schema, r io.Reader) error {
+	v, err := NewValidator(s)
+
+	if err != nil {
+		return err
+	}
+
+	violations, err := v.ValidateJSON(r)
+
+	if err != nil {
+		return err
+	}
+
+	return toError(violations)
+}
+
+// Validate validates the given record, either a map keyed by field name or a
+// struct mapped by `instapi` tags.
+func (v *Validator) Validate(rec interface{}) []*Violation {
+	v.row++
+	m, err := toMap(rec)
+
+	if err != nil {
+		return []*Violation{{Row: v.row, Err: err}}
+	}
+
+	return v.validate(m, false, false)
+}
+
+// ValidateCSV validates the CSV records read from r. The first row is
+// expected to hold the column headers, matched against the field mapping or
+// name.
+func (v *Validator) ValidateCSV(r io.Reader) ([]*Violation, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	if v.schema.Settings != nil && v.schema.Settings.Delimiter != "" {
+		cr.Comma = []rune(v.schema.Settings.Delimiter)[0]
+	}
+
+	header, err := cr.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	copy(columns, header)
+
+	var violations []*Violation
+
+	for {
+		row, err := cr.Read()
+
+		if err == io.EOF {
+			return violations, nil
+		}
+
+		if err != nil {
+			return violations, err
+		}
+
+		m := make(map[string]interface{}, len(columns))
+
+		for i, c := range columns {
+			if i < len(row) {
+				m[c] = row[i]
+			}
+		}
+
+		v.row++
+		violations = append(violations, v.validate(m, true, true)...)
+	}
+}
+
+// ValidateJSON validates the JSON array or newline delimited JSON records read
+// from r.
+func (v *Validator) ValidateJSON(r io.Reader) ([]*Violation, error) {
+	br := bufio.NewReader(r)
+	b, err := firstByte(br)
+
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+
+	var (
+		violations []*Violation
+		array      bool
+	)
+
+	if b == '[' {
+		_, err = dec.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		array = true
+	}
+
+	for dec.More() {
+		var m map[string]interface{}
+		err = dec.Decode(&m)
+
+		if err != nil {
+			return violations, err
+		}
+
+		v.row++
+		violations = append(violations, v.validate(m, true, false)...)
+	}
+
+	if array {
+		_, err = dec.Token()
+	}
+
+	return violations, err
+}
+
+func (v *Validator) validate(m map[string]interface{}, mapped, text bool) []*Violation {
+	var violations []*Violation
+
+	for _, f := range v.schema.Fields {
+		key := f.Name
+
+		if mapped && f.Mapping != "" {
+			key = f.Mapping
+		}
+
+		value, exists := m[key]
+
+		if !exists || isEmpty(value, text) {
+			// Missing primary key values are reported as ErrMissingKey
+			if f.Required && !v.primaryKey[f.Name] {
+				violations = append(violations, &Violation{Row: v.row, Field: f.Name, Err: ErrRequired})
+			}
+
+			continue
+		}
+
+		err := v.checkField(f, value, text)
+
+		if err != nil {
+			violations = append(violations, &Violation{Row: v.row, Field: f.Name, Value: value, Err: err})
+		}
+	}
+
+	if len(v.schema.PrimaryKey) == 0 {
+		return violations
+	}
+
+	var (
+		key     = make([]string, 0, len(v.schema.PrimaryKey))
+		missing bool
+	)
+
+	for _, name := range v.schema.PrimaryKey {
+		value := m[name]
+
+		if f := v.schema.Field(name); mapped && f != nil && f.Mapping != "" {
+			value = m[f.Mapping]
+		}
+
+		if isEmpty(value, text) {
+			violations = append(violations, &Violation{Row: v.row, Field: name, Err: ErrMissingKey})
+			missing = true
+
+			continue
+		}
+
+		key = append(key, fmt.Sprint(value))
+	}
+
+	if missing {
+		return violations
+	}
+
+	k := strings.Join(key, "\x00")
+
+	if row, exists := v.keys[k]; exists {
+		violations = append(violations, &Violation{
+			Row:   v.row,
+			Field: strings.Join(v.schema.PrimaryKey, ","),
+			Value: strings.Join(key, ","),
+			Err:   fmt.Errorf("%w: first seen at row %d", ErrDuplicateKey, row),
+		})
+	} else {
+		v.keys[k] = v.row
+	}
+
+	return violations
+}
+
+func (v *Validator) checkField(f *Field, value interface{}, text bool) error {
+	err := checkType(f, value)
+
+	if err != nil {
+		return err
+	}
+
+	// JSON values read as text must be JSON encoded
+	if s, ok := value.(string); ok && text && f.Type == JSON && !json.Valid([]byte(s)) {
+		return fmt.Errorf("%w: expected json, got %q", ErrType, s)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	re, exists := v.formats[f.Name]
+
+	if exists && !re.MatchString(fmt.Sprint(value)) {
+		return fmt.Errorf("%w: %q does not match %s", ErrFormat, fmt.Sprint(value), re)
+	}
+
+	return nil
+}
+
+// CheckValue checks that the value is of the field type, regardless of the
+// field format. Strings are parsed as numeric, boolean and temporal values.
+func (f *Field) CheckValue(value interface{}) error {
+	return checkType(f, value)
+}
+
+func checkType(f *Field, value interface{}) error {
+	switch {
+	case f.Type == Integer:
+		if !isInteger(value) {
+			return fmt.Errorf("%w: expected integer, got %v", ErrType, value)
+		}
+
+	case f.Type.IsNumeric():
+		if !isNumber(value) {
+			return fmt.Errorf("%w: expected number, got %v", ErrType, value)
+		}
+
+	case f.Type == Boolean:
+		if !isBoolean(value) {
+			return fmt.Errorf("%w: expected boolean, got %v", ErrType, value)
+		}
+
+	case f.Type.IsTemporal():
+		return checkTime(f, value)
+
+	case f.Type.IsText():
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%w: expected string, got %T", ErrType, value)
+		}
+
+	case f.Type == Object:
+		if !isObject(value) {
+			return fmt.Errorf("%w: expected object, got %T", ErrType, value)
+		}
+
+	case f.Type == Array:
+		if !isArray(value) {
+			return fmt.Errorf("%w: expected array, got %T", ErrType, value)
+		}
+
+	case f.Type == JSON:
+		if !isJSON(value) {
+			return fmt.Errorf("%w: expected json, got %T", ErrType, value)
+		}
+	}
+
+	return nil
+}
+
+func checkTime(f *Field, value interface{}) error {
+	switch v := value.(type) {
+	case time.Time, *time.Time:
+		return nil
+
+	case string:
+		_, err := f.Parse(v)
+
+		return err
+	}
+
+	return fmt.Errorf("%w: expected %s, got %T", ErrType, f.Type, value)
+}
+
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case json.Number:
+		_, err := v.Int64()
+
+		return err == nil
+
+	case string:
+		_, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+
+		return err == nil
+
+	case float64:
+		return v == math.Trunc(v)
+
+	case float32:
+		return float64(v) == math.Trunc(float64(v))
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+func isNumber(value interface{}) bool {
+	switch v := value.(type) {
+	case json.Number:
+		_, err := v.Float64()
+
+		return err == nil
+
+	case string:
+		_, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+
+		return err == nil
+
+	case float32, float64:
+		return true
+	}
+
+	return isInteger(value)
+}
+
+func isBoolean(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return true
+
+	case string:
+		_, err := strconv.ParseBool(strings.TrimSpace(v))
+
+		return err == nil
+	}
+
+	return false
+}
+
+// isObject reports whether the value is a map or struct, or a JSON object
+// encoded as a string.
+func isObject(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		_, err := Object.Parse(v, "")
+
+		return err == nil
+
+	case json.RawMessage:
+		_, err := Object.Parse(string(v), "")
+
+		return err == nil
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Struct:
+		return true
+	}
+
+	return false
+}
+
+// isArray reports whether the value is a slice or array, or a JSON array
+// encoded as a string.
+func isArray(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		_, err := Array.Parse(v, "")
+
+		return err == nil
+
+	case json.RawMessage:
+		_, err := Array.Parse(string(v), "")
+
+		return err == nil
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	}
+
+	return false
+}
+
+// isJSON reports whether the value can be encoded as JSON.
+func isJSON(value interface{}) bool {
+	if v, ok := value.(json.RawMessage); ok {
+		return json.Valid(v)
+	}
+
+	_, err := json.Marshal(value)
+
+	return err == nil
+}
+
+func isEmpty(value interface{}, text bool) bool {
+	if value == nil {
+		return true
+	}
+
+	if s, ok := value.(string); ok {
+		return text && s == ""
+	}
+
+	rv := reflect.ValueOf(value)
+
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+func toMap(rec interface{}) (map[string]interface{}, error) {
+	switch v := rec.(type) {
+	case map[string]interface{}:
+		return v, nil
+
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+
+		for k, s := range v {
+			m[k] = s
+		}
+
+		return m, nil
+	}
+
+	mapping, err := record.MappingOf(rec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := mapping.Encode(rec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for k, value := range m {
+		rv := reflect.ValueOf(value)
+
+		if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			m[k] = rv.Elem().Interface()
+		}
+	}
+
+	return m, nil
+}
+
+func toError(violations []*Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: violations}
+}
+
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+
+		if err != nil {
+			return 0, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return b, r.UnreadByte()
+	}
+}
@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSchema() *Schema {
+	code := "^[A-Z]+$"
+
+	return &Schema{
+		Name:       "companies",
+		PrimaryKey: []string{"id"},
+		Fields: []*Field{
+			{Name: "id", Type: "integer", Required: true},
+			{Name: "code", Type: "string", Format: &code},
+			{Name: "name", Type: "string", Required: true},
+			{Name: "founded", Type: "date"},
+			{Name: "active", Type: "boolean"},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := testSchema()
+
+	require.NoError(t, Validate(s, map[string]interface{}{"id": 1, "name": "Acme", "code": "ACME"}))
+
+	err := Validate(s, map[string]interface{}{"id": 1.5, "code": "acme", "active": "yes"})
+
+	var verr *ValidationError
+
+	require.ErrorAs(t, err, &verr)
+	require.ErrorIs(t, err, ErrInvalid)
+	require.Len(t, verr.Violations, 4)
+	require.ErrorIs(t, verr.Violations[0], ErrType)
+	require.ErrorIs(t, verr.Violations[1], ErrFormat)
+	require.ErrorIs(t, verr.Violations[2], ErrRequired)
+	require.ErrorIs(t, verr.Violations[3], ErrType)
+}
+
+func TestValidateCSV(t *testing.T) {
+	const data = "id,code,name,founded,active\n" +
+		"1,ACME,Acme,2001-02-03,true\n" +
+		"2,ACME,,2001-02-30,false\n" +
+		"1,ACME,Dupe,,\n" +
+		",ACME,Nokey,,\n"
+
+	err := ValidateCSV(testSchema(), strings.NewReader(data))
+
+	var verr *ValidationError
+
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Violations, 4)
+
+	for i, v := range []struct {
+		row   int
+		field string
+		err   error
+	}{
+		{2, "name", ErrRequired},
+		{2, "founded", ErrFormat},
+		{3, "id", ErrDuplicateKey},
+		{4, "id", ErrMissingKey},
+	} {
+		require.Equal(t, v.row, verr.Violations[i].Row)
+		require.Equal(t, v.field, verr.Violations[i].Field)
+		require.ErrorIs(t, verr.Violations[i], v.err)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	for _, data := range []string{
+		`[{"id": 1, "name": "Acme"}, {"id": 2, "name": "Other"}, {"id": 2, "name": 3}]`,
+		"{\"id\": 1, \"name\": \"Acme\"}\n{\"id\": 2, \"name\": \"Other\"}\n{\"id\": 2, \"name\": 3}\n",
+	} {
+		err := ValidateJSON(testSchema(), strings.NewReader(data))
+
+		var verr *ValidationError
+
+		require.ErrorAs(t, err, &verr)
+		require.Len(t, verr.Violations, 2)
+		require.ErrorIs(t, verr.Violations[0], ErrType)
+		require.ErrorIs(t, verr.Violations[1], ErrDuplicateKey)
+		require.Equal(t, 3, verr.Violations[1].Row)
+	}
+}
+
+func TestValidateMissingKeys(t *testing.T) {
+	s := &Schema{
+		Name:       "lines",
+		PrimaryKey: []string{"order", "line"},
+		Fields: []*Field{
+			{Name: "order", Type: "integer", Required: true},
+			{Name: "line", Type: "integer", Required: true},
+		},
+	}
+
+	err := Validate(s, map[string]interface{}{})
+
+	var verr *ValidationError
+
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Violations, 2)
+
+	for i, name := range []string{"order", "line"} {
+		require.Equal(t, name, verr.Violations[i].Field)
+		require.ErrorIs(t, verr.Violations[i], ErrMissingKey)
+	}
+}
+
+func TestValidateStructured(t *testing.T) {
+	s := &Schema{
+		Name: "events",
+		Fields: []*Field{
+			{Name: "meta", Type: "object"},
+			{Name: "tags", Type: "array"},
+			{Name: "raw", Type: "json"},
+		},
+	}
+
+	for _, rec := range []map[string]interface{}{
+		{"meta": map[string]interface{}{"x": 1}, "tags": []interface{}{"a"}, "raw": map[string]interface{}{"x": 1}},
+		{"meta": struct{ X int }{1}, "tags": []string{"a"}, "raw": "text"},
+		{"meta": `{"x": 1}`, "tags": `["a"]`, "raw": 1},
+	} {
+		require.NoError(t, Validate(s, rec))
+	}
+
+	err := Validate(s, map[string]interface{}{"meta": 1, "tags": "a", "raw": func() {}})
+
+	var verr *ValidationError
+
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Violations, 3)
+
+	for i, name := range []string{"meta", "tags", "raw"} {
+		require.Equal(t, name, verr.Violations[i].Field)
+		require.ErrorIs(t, verr.Violations[i], ErrType)
+	}
+
+	// Values read as text must be encoded as JSON
+	err = ValidateCSV(s, strings.NewReader("meta,tags,raw\n\"{\"\"x\"\": 1}\",[],{}\n[],{},text\n"))
+
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Violations, 3)
+
+	for i, name := range []string{"meta", "tags", "raw"} {
+		require.Equal(t, 2, verr.Violations[i].Row)
+		require.Equal(t, name, verr.Violations[i].Field)
+		require.ErrorIs(t, verr.Violations[i], ErrType)
+	}
+}
@@ -2,9 +2,20 @@ package instapi
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/instapi/client-go/schema"
+	"github.com/instapi/client-go/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,3 +39,197 @@ func TestDetectSchemaForFile(t *testing.T) {
 	require.NoError(t, err)
 	spew.Dump(schema)
 }
+
+func TestDetectSchemasTranscoderExits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	var (
+		c        = New(Endpoint(srv.URL + "/"))
+		ctx      = context.Background()
+		input    = strings.Repeat("{\"id\":1,\"name\":\"foo\"}\n", 300000)
+		filename = filepath.Join(t.TempDir(), "data.ndjson")
+	)
+
+	require.NoError(t, os.WriteFile(filename, []byte(input), 0600))
+
+	// Warm up the connection, whose goroutines are kept alive
+	_, err := c.DetectSchemas(ctx, "test", types.NDJSON, strings.NewReader(input), SizeLimit(1024))
+	require.NoError(t, err)
+
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		_, err = c.DetectSchemas(ctx, "test", types.NDJSON, strings.NewReader(input), SizeLimit(1024))
+		require.NoError(t, err)
+
+		_, err = c.ImportSchemasFromFile(ctx, "test", filename)
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestSchemaUnknownFieldType(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"name":"places","fields":[{"name":"id","type":"integer"},{"name":"area","type":"geometry"}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(Endpoint(srv.URL + "/"))
+	s, err := c.GetSchema(context.Background(), "test", "places")
+
+	require.NoError(t, err)
+	require.Equal(t, schema.FieldType("geometry"), s.Fields[1].Type)
+
+	err = c.UpdateSchema(context.Background(), "test", "places", s)
+
+	require.ErrorIs(t, err, schema.ErrUnknownType)
+	require.Equal(t, 1, requests)
+}
+
+func TestApplyMigrationRename(t *testing.T) {
+	from := &schema.Schema{Name: "people", Fields: []*schema.Field{
+		{Name: "id", Type: schema.Integer, Required: true},
+		{Name: "name", Type: schema.String, Required: true},
+	}}
+	to := &schema.Schema{Name: "people", Fields: []*schema.Field{
+		{Name: "id", Type: schema.Integer, Required: true},
+		{Name: "fullName", Type: schema.String, Required: true},
+		{Name: "country", Type: schema.String},
+	}}
+
+	api, c := newFakeAPI(t, from)
+
+	for i := 0; i < backfillBatchSize+1; i++ {
+		id := strconv.Itoa(i)
+		api.records["people"] = append(api.records["people"], map[string]interface{}{"instapi:id": id, "id": i, "name": "name " + id})
+	}
+
+	p := schema.Diff(from, to, schema.Renamed("name", "fullName"))
+	p.AllowBreaking = true
+	p.Backfill = map[string]interface{}{"country": "NZ"}
+
+	require.Equal(t, map[string]string{"name": "fullName"}, p.Renames())
+
+	// Paging options of the caller are ignored
+	require.NoError(t, c.ApplyMigration(context.Background(), "test", p, Limit(1), Skip(5)))
+
+	records := api.records["people"]
+
+	require.Len(t, records, backfillBatchSize+1)
+
+	for i, v := range records {
+		require.Equal(t, map[string]interface{}{
+			"instapi:id": strconv.Itoa(i),
+			"id":         i,
+			"fullName":   "name " + strconv.Itoa(i),
+			"country":    "NZ",
+		}, v)
+	}
+
+	require.Equal(t, to, api.schemas["people"])
+
+	requests := api.log()
+
+	require.Equal(t, "PUT /accounts/test/schemas/people", requests[0])
+	require.Equal(t, "GET /accounts/test/schemas/people/records?limit=100&skip=0", requests[1])
+	require.Equal(t, "GET /accounts/test/schemas/people/records?limit=100&skip=100", requests[102])
+	require.Equal(t, "PUT /accounts/test/schemas/people", requests[104])
+}
+
+func TestApplyMigrationRenameConflict(t *testing.T) {
+	from := &schema.Schema{Name: "people", Fields: []*schema.Field{{Name: "a", Type: schema.String}, {Name: "b", Type: schema.String}}}
+	to := &schema.Schema{Name: "people", Fields: []*schema.Field{{Name: "b", Type: schema.String}, {Name: "a", Type: schema.String}}}
+
+	api, c := newFakeAPI(t, from)
+	p := schema.Diff(from, to, schema.Renamed("a", "b"), schema.Renamed("b", "a"))
+	p.AllowBreaking = true
+
+	require.ErrorIs(t, c.ApplyMigration(context.Background(), "test", p), ErrRenameConflict)
+	require.Empty(t, api.log())
+}
+
+func TestDetectSchemasDelimited(t *testing.T) {
+	var body string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	var (
+		c   = New(Endpoint(srv.URL + "/"))
+		ctx = context.Background()
+	)
+
+	// TSV is sampled once transcoded into CSV
+	_, err := c.DetectSchemas(ctx, "test", types.TSV, strings.NewReader("id\tname\n1\ta,b\n2\t\"x\"\n3\tc\n"), SizeLimit(18))
+
+	require.NoError(t, err)
+	require.Equal(t, "id,name\n1,\"a,b\"\n", body)
+
+	// Quoted fields holding the delimiter are kept as is
+	data := "id;name\n1;\"x;y\"\n2;z\n"
+
+	_, err = c.DetectSchemas(ctx, "test", types.CSV, strings.NewReader(data), Delimiter(';'))
+
+	require.NoError(t, err)
+	require.Equal(t, data, body)
+
+	cmp, err := c.CompareDetection(ctx, "test", types.CSV, strings.NewReader(data), Delimiter(';'))
+
+	require.NoError(t, err)
+	require.Len(t, cmp.Local[0].Fields, 2)
+}
+
+func TestParquetInput(t *testing.T) {
+	var (
+		contentTypes []string
+		bodies       []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		bodies = append(bodies, string(b))
+
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/records") {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"count":2}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	var (
+		c    = New(Endpoint(srv.URL + "/"))
+		ctx  = context.Background()
+		data = "PAR1" + strings.Repeat("\x00", 64) + "PAR1"
+	)
+
+	// Parquet files are sent whole, despite the size limit
+	_, err := c.DetectSchemas(ctx, "test", types.Parquet, strings.NewReader(data), SizeLimit(8))
+	require.NoError(t, err)
+
+	n, err := c.CreateRecords(ctx, "acme", "test", types.Parquet, strings.NewReader(data))
+
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []string{types.Parquet, types.Parquet}, contentTypes)
+	require.Equal(t, []string{data, data}, bodies)
+}
@@ -0,0 +1,290 @@
+package instapi
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// Sync action kinds.
+const (
+	SyncCreate    = "create"
+	SyncUpdate    = "update"
+	SyncDelete    = "delete"
+	SyncUnmanaged = "unmanaged"
+)
+
+// SyncAction represents an action reconciling a single schema.
+type SyncAction struct {
+	Kind   string
+	Name   string
+	Schema *schema.Schema
+	Plan   *schema.Plan
+}
+
+func (a *SyncAction) String() string {
+	switch a.Kind {
+	case SyncCreate:
+		return fmt.Sprintf("+ create %s (%d fields)", a.Name, len(a.Schema.Fields))
+
+	case SyncDelete:
+		return fmt.Sprintf("- delete %s", a.Name)
+
+	case SyncUnmanaged:
+		return fmt.Sprintf("? unmanaged %s (not in desired state, prune disabled)", a.Name)
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "~ update %s", a.Name)
+
+	for _, c := range a.Plan.Changes {
+		sb.WriteString("\n    ")
+		sb.WriteString(c.String())
+	}
+
+	return sb.String()
+}
+
+// SyncPlan represents the actions required to reconcile the schemas of an
+// account with a desired state.
+type SyncPlan struct {
+	Account string
+	Actions []*SyncAction
+}
+
+// Empty reports whether the plan has no actions to apply.
+func (p *SyncPlan) Empty() bool {
+	for _, a := range p.Actions {
+		if a.Kind != SyncUnmanaged {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *SyncPlan) String() string {
+	if len(p.Actions) == 0 {
+		return fmt.Sprintf("account %s: no changes", p.Account)
+	}
+
+	counts := map[string]int{}
+
+	for _, a := range p.Actions {
+		counts[a.Kind]++
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "account %s: %d to create, %d to update, %d to delete",
+		p.Account, counts[SyncCreate], counts[SyncUpdate], counts[SyncDelete])
+
+	for _, a := range p.Actions {
+		sb.WriteString("\n")
+		sb.WriteString(a.String())
+	}
+
+	return sb.String()
+}
+
+// SyncOption represents a sync option.
+type SyncOption func(*syncOptions)
+
+type syncOptions struct {
+	prune         bool
+	dryRun        bool
+	allowBreaking bool
+	request       []RequestOption
+}
+
+// Prune option deletes schemas missing from the desired state. Without it,
+// such schemas are reported as unmanaged and left untouched.
+func Prune(prune bool) SyncOption {
+	return func(o *syncOptions) {
+		o.prune = prune
+	}
+}
+
+// DryRunSync option computes the plan without applying it.
+func DryRunSync(dryRun bool) SyncOption {
+	return func(o *syncOptions) {
+		o.dryRun = dryRun
+	}
+}
+
+// AllowBreaking option allows applying updates with breaking changes.
+func AllowBreaking(allow bool) SyncOption {
+	return func(o *syncOptions) {
+		o.allowBreaking = allow
+	}
+}
+
+// SyncRequestOptions option sets the request options used for API calls.
+func SyncRequestOptions(options ...RequestOption) SyncOption {
+	return func(o *syncOptions) {
+		o.request = append(o.request, options...)
+	}
+}
+
+func newSyncOptions(options []SyncOption) *syncOptions {
+	o := &syncOptions{}
+
+	for _, option := range options {
+		option(o)
+	}
+
+	return o
+}
+
+// PlanSync computes the actions reconciling the account schemas with the
+// desired schemas. Settings and field mappings left unset by a desired schema
+// are populated by the API, and kept as is.
+func (c *Client) PlanSync(ctx context.Context, account string, desired []*schema.Schema, options ...SyncOption) (*SyncPlan, error) {
+	o := newSyncOptions(options)
+	current, err := c.GetAllSchemas(ctx, account, o.request...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*schema.Schema, len(current))
+
+	for _, v := range current {
+		existing[v.Name] = v
+	}
+
+	p := &SyncPlan{Account: account}
+	wanted := make(map[string]bool, len(desired))
+
+	for _, v := range desired {
+		if wanted[v.Name] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateSchema, v.Name)
+		}
+
+		wanted[v.Name] = true
+		s, exists := existing[v.Name]
+
+		if !exists {
+			p.Actions = append(p.Actions, &SyncAction{Kind: SyncCreate, Name: v.Name, Schema: v})
+			continue
+		}
+
+		v = normalize(v, s)
+		plan := schema.Diff(s, v)
+
+		if plan.Empty() {
+			continue
+		}
+
+		plan.AllowBreaking = o.allowBreaking
+		p.Actions = append(p.Actions, &SyncAction{Kind: SyncUpdate, Name: v.Name, Schema: v, Plan: plan})
+	}
+
+	for _, v := range current {
+		if wanted[v.Name] {
+			continue
+		}
+
+		kind := SyncUnmanaged
+
+		if o.prune {
+			kind = SyncDelete
+		}
+
+		p.Actions = append(p.Actions, &SyncAction{Kind: kind, Name: v.Name, Schema: v})
+	}
+
+	order := map[string]int{SyncCreate: 0, SyncUpdate: 1, SyncDelete: 2, SyncUnmanaged: 3}
+
+	sort.SliceStable(p.Actions, func(i, j int) bool {
+		return order[p.Actions[i].Kind] < order[p.Actions[j].Kind]
+	})
+
+	return p, nil
+}
+
+// normalize returns a copy of the desired schema holding the settings and
+// field mappings of the current schema it leaves unset, e.g. the delimiter
+// and column headers set by the API on import.
+func normalize(desired, current *schema.Schema) *schema.Schema {
+	s := *desired
+	s.Fields = make([]*schema.Field, len(desired.Fields))
+
+	for i, v := range desired.Fields {
+		f := *v
+
+		if n := current.Field(f.Name); n != nil && f.Mapping == "" {
+			f.Mapping = n.Mapping
+		}
+
+		s.Fields[i] = &f
+	}
+
+	if current.Settings == nil {
+		return &s
+	}
+
+	settings := *current.Settings
+
+	if desired.Settings != nil {
+		dst, src := reflect.ValueOf(&settings).Elem(), reflect.ValueOf(*desired.Settings)
+
+		for i := 0; i < src.NumField(); i++ {
+			if !src.Field(i).IsZero() {
+				dst.Field(i).Set(src.Field(i))
+			}
+		}
+	}
+
+	s.Settings = &settings
+
+	return &s
+}
+
+// ApplySync applies the given sync plan. Breaking updates are rejected
+// unless allowed when planning.
+func (c *Client) ApplySync(ctx context.Context, p *SyncPlan, options ...RequestOption) error {
+	for _, a := range p.Actions {
+		var err error
+
+		switch a.Kind {
+		case SyncCreate:
+			err = c.CreateSchema(ctx, p.Account, a.Schema, options...)
+
+		case SyncUpdate:
+			err = c.ApplyMigration(ctx, p.Account, a.Plan, options...)
+
+		case SyncDelete:
+			err = c.DeleteSchema(ctx, p.Account, a.Name, options...)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", a.Kind, a.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Sync reconciles the account schemas with the desired schemas, returning
+// the applied plan. With DryRunSync the plan is returned without applying it.
+func (c *Client) Sync(ctx context.Context, account string, desired []*schema.Schema, options ...SyncOption) (*SyncPlan, error) {
+	p, err := c.PlanSync(ctx, account, desired, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o := newSyncOptions(options)
+
+	if o.dryRun {
+		return p, nil
+	}
+
+	return p, c.ApplySync(ctx, p, o.request...)
+}
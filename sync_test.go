@@ -0,0 +1,138 @@
+package instapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/instapi/client-go/schema"
+	"github.com/stretchr/testify/require"
+)
+
+// syncSchemas returns the current schemas as populated by the API, and the
+// desired schemas as read from files.
+func syncSchemas() ([]*schema.Schema, []*schema.Schema) {
+	current := []*schema.Schema{
+		{
+			Name:     "companies",
+			Count:    10,
+			Settings: &schema.Settings{Delimiter: ",", QuoteValues: "auto"},
+			Fields: []*schema.Field{
+				{Name: "id", Mapping: "ID", Type: schema.Integer, Required: true},
+				{Name: "name", Mapping: "Name", Type: schema.String},
+			},
+		},
+		{Name: "people", Fields: []*schema.Field{{Name: "id", Mapping: "id", Type: schema.Integer}}},
+		{Name: "legacy", Fields: []*schema.Field{{Name: "id", Type: schema.Integer}}},
+	}
+
+	desired := []*schema.Schema{
+		{
+			Name: "companies",
+			Fields: []*schema.Field{
+				{Name: "id", Type: schema.Integer, Required: true},
+				{Name: "name", Type: schema.String},
+			},
+		},
+		{Name: "people", Fields: []*schema.Field{{Name: "id", Type: schema.Integer}, {Name: "email", Type: schema.Email}}},
+		{Name: "orders", Fields: []*schema.Field{{Name: "id", Type: schema.Integer}}},
+	}
+
+	return current, desired
+}
+
+func TestPlanSync(t *testing.T) {
+	current, desired := syncSchemas()
+	api, c := newFakeAPI(t, current...)
+
+	p, err := c.PlanSync(context.Background(), "test", desired)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /accounts/test/schemas"}, api.log())
+
+	// Server populated settings and mappings do not show as changes
+	require.Equal(t, `account test: 1 to create, 1 to update, 0 to delete
++ create orders (1 fields)
+~ update people
+      field added email: <nil> -> email
+? unmanaged legacy (not in desired state, prune disabled)`, p.String())
+	require.False(t, p.Empty())
+
+	update := p.Actions[1]
+
+	require.Equal(t, "id", update.Plan.To.Fields[0].Mapping)
+	require.Empty(t, desired[1].Fields[0].Mapping)
+
+	p, err = c.PlanSync(context.Background(), "test", desired, Prune(true))
+
+	require.NoError(t, err)
+	require.Equal(t, SyncDelete, p.Actions[2].Kind)
+	require.Equal(t, "legacy", p.Actions[2].Name)
+
+	_, err = c.PlanSync(context.Background(), "test", append(desired, desired[0]))
+	require.ErrorIs(t, err, ErrDuplicateSchema)
+}
+
+func TestSync(t *testing.T) {
+	current, desired := syncSchemas()
+	api, c := newFakeAPI(t, current...)
+
+	p, err := c.Sync(context.Background(), "test", desired, DryRunSync(true))
+
+	require.NoError(t, err)
+	require.False(t, p.Empty())
+	require.Equal(t, []string{"GET /accounts/test/schemas"}, api.log())
+
+	// Unmanaged schemas are not deleted without pruning
+	_, err = c.Sync(context.Background(), "test", desired)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"GET /accounts/test/schemas",
+		"POST /accounts/test/schemas",
+		"PUT /accounts/test/schemas/people",
+	}, api.log())
+	require.Contains(t, api.schemas, "legacy")
+	require.Contains(t, api.schemas, "orders")
+	require.NotNil(t, api.schemas["people"].Field("email"))
+
+	p, err = c.PlanSync(context.Background(), "test", desired)
+
+	require.NoError(t, err)
+	require.Len(t, p.Actions, 1)
+	require.Equal(t, SyncUnmanaged, p.Actions[0].Kind)
+	require.True(t, p.Empty())
+
+	api.log()
+	require.NoError(t, c.ApplySync(context.Background(), p))
+	require.Empty(t, api.log())
+	require.Contains(t, api.schemas, "legacy")
+
+	_, err = c.Sync(context.Background(), "test", desired, Prune(true))
+
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"GET /accounts/test/schemas",
+		"DELETE /accounts/test/schemas/legacy",
+	}, api.log())
+	require.NotContains(t, api.schemas, "legacy")
+}
+
+func TestSyncBreaking(t *testing.T) {
+	current, desired := syncSchemas()
+	desired[0].Fields[1].Type = schema.Integer
+
+	api, c := newFakeAPI(t, current...)
+	_, err := c.Sync(context.Background(), "test", desired[:1])
+
+	require.ErrorIs(t, err, ErrBreakingChange)
+	require.Equal(t, []string{"GET /accounts/test/schemas"}, api.log())
+
+	_, err = c.Sync(context.Background(), "test", desired[:1], AllowBreaking(true))
+
+	require.NoError(t, err)
+	require.Equal(t, schema.Integer, api.schemas["companies"].Fields[1].Type)
+
+	// Settings and mappings populated by the API are kept
+	require.Equal(t, &schema.Settings{Delimiter: ",", QuoteValues: "auto"}, api.schemas["companies"].Settings)
+	require.Equal(t, "Name", api.schemas["companies"].Fields[1].Mapping)
+}
@@ -0,0 +1,333 @@
+package instapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timeout related errors.
+var (
+	ErrTimeout = errors.New("request timed out")
+)
+
+// Operation represents a class of API operations sharing a timeout.
+type Operation string
+
+// Operation classes.
+const (
+	// ReadOperation is any GET request, e.g. GetSchema.
+	ReadOperation Operation = "read"
+	// WriteOperation is any other request, e.g. UpdateSchema.
+	WriteOperation Operation = "write"
+	// QueryOperation is a SQL query, e.g. Query or QueryRows.
+	QueryOperation Operation = "query"
+	// UploadOperation is a request streaming a reader, e.g.
+	// CreateRecordsFromFile.
+	UploadOperation Operation = "upload"
+)
+
+type timeouts struct {
+	timeout    time.Duration
+	operations map[Operation]time.Duration
+	connect    time.Duration
+	firstByte  time.Duration
+	idle       time.Duration
+}
+
+// Timeout option sets the default time limit of requests, including reading
+// the response body. Zero means no limit.
+func Timeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeouts.timeout = d
+	}
+}
+
+// OperationTimeout option sets the time limit of requests of the given
+// operation class, overriding the default Timeout.
+func OperationTimeout(op Operation, d time.Duration) ClientOption {
+	return func(c *Client) {
+		if c.timeouts.operations == nil {
+			c.timeouts.operations = map[Operation]time.Duration{}
+		}
+
+		c.timeouts.operations[op] = d
+	}
+}
+
+// ConnectTimeout option sets the time limit of establishing connections. It
+// applies to the default HTTP client only, not one set with HTTPClient.
+func ConnectTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeouts.connect = d
+	}
+}
+
+// FirstByteTimeout option sets the time limit of receiving the response
+// headers once the request has been sent.
+func FirstByteTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeouts.firstByte = d
+	}
+}
+
+// IdleTimeout option sets the time limit without progress sending the
+// request body or reading the response body, detecting stalled uploads and
+// downloads.
+func IdleTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeouts.idle = d
+	}
+}
+
+// RequestTimeout sets the time limit of the request, overriding the client
+// timeouts.
+func RequestTimeout(d time.Duration) RequestOption {
+	return clientParam("timeout", d)
+}
+
+// defaultDoer returns the default HTTP client, dialing with the connect
+// timeout if any.
+func (t *timeouts) defaultDoer() Doer {
+	if t.connect == 0 {
+		return http.DefaultClient
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: t.connect, KeepAlive: 30 * time.Second}).DialContext
+
+	return &http.Client{Transport: transport}
+}
+
+// operation returns the operation class of the request.
+func (c *Client) operation(method, endpoint string, src interface{}) Operation {
+	if strings.HasPrefix(strings.TrimPrefix(endpoint, c.endpoint), "query") {
+		return QueryOperation
+	}
+
+	if _, ok := src.(io.Reader); ok {
+		return UploadOperation
+	}
+
+	if method == http.MethodGet {
+		return ReadOperation
+	}
+
+	return WriteOperation
+}
+
+// watch returns the context of the request limited by the timeouts, along
+// with a watchdog enforcing the progress timeouts, or a nil watchdog when no
+// timeouts apply.
+func (c *Client) watch(ctx context.Context, op Operation, options []RequestOption) (context.Context, *watchdog) {
+	d, exists := c.timeouts.operations[op]
+
+	if !exists {
+		d = c.timeouts.timeout
+	}
+
+	if v, exists := findOption(options, "timeout"); exists {
+		d = v.(time.Duration)
+	}
+
+	if d == 0 && c.timeouts.firstByte == 0 && c.timeouts.idle == 0 {
+		return ctx, nil
+	}
+
+	var cancel context.CancelFunc
+
+	if d > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	return ctx, &watchdog{cancel: cancel, firstByte: c.timeouts.firstByte, idle: c.timeouts.idle}
+}
+
+// Watchdog phases, which only advance until the next request.
+const (
+	phaseSending = iota
+	phaseSent
+	phaseReceiving
+	phaseDone
+)
+
+// watchdog cancels a request making no progress. The idle timer runs while
+// sending the request body and reading the response body, and the first byte
+// timer between sending the request and receiving the response headers.
+type watchdog struct {
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	firstByte time.Duration
+	idle      time.Duration
+	timer     *time.Timer
+	phase     int
+	gen       int
+	err       error
+}
+
+// arm restarts the timer for the phase, failing with err after d. Zero stops
+// the timer. Events of earlier phases are ignored.
+func (w *watchdog) arm(phase int, d time.Duration, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if phase < w.phase {
+		return
+	}
+
+	w.phase = phase
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+
+	w.gen++
+
+	if d == 0 || w.err != nil {
+		return
+	}
+
+	gen := w.gen
+	w.timer = time.AfterFunc(d, func() {
+		w.mu.Lock()
+		fired := gen == w.gen
+
+		if fired {
+			w.err = err
+		}
+
+		w.mu.Unlock()
+
+		if fired {
+			w.cancel()
+		}
+	})
+}
+
+// sending arms the idle timer for the request body.
+func (w *watchdog) sending() {
+	w.arm(phaseSending, w.idle, fmt.Errorf("%w: request body stalled for %s", ErrTimeout, w.idle))
+}
+
+// sent arms the first byte timer.
+func (w *watchdog) sent() {
+	w.arm(phaseSent, w.firstByte, fmt.Errorf("%w: no response within %s", ErrTimeout, w.firstByte))
+}
+
+// receiving arms the idle timer for the response body.
+func (w *watchdog) receiving() {
+	w.arm(phaseReceiving, w.idle, fmt.Errorf("%w: response body stalled for %s", ErrTimeout, w.idle))
+}
+
+// stop stops the timer and releases the context.
+func (w *watchdog) stop() {
+	w.arm(phaseDone, 0, nil)
+	w.cancel()
+}
+
+// failure returns the timeout error in place of the given error, if the
+// watchdog fired.
+func (w *watchdog) failure(err error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+
+	return err
+}
+
+// watchRequest tracks the progress of sending the request.
+func (w *watchdog) watchRequest(req *http.Request) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	w.phase = phaseSending
+	w.mu.Unlock()
+
+	if req.Body == nil || req.Body == http.NoBody {
+		w.sent()
+		return
+	}
+
+	req.Body = &watchedRequest{ReadCloser: req.Body, w: w}
+	w.sending()
+}
+
+// watchResponse tracks the progress of reading the response body.
+func (w *watchdog) watchResponse(body io.ReadCloser) io.ReadCloser {
+	if w == nil {
+		return body
+	}
+
+	w.receiving()
+
+	return &watchedResponse{ReadCloser: body, w: w}
+}
+
+type watchedRequest struct {
+	io.ReadCloser
+	w *watchdog
+}
+
+func (b *watchedRequest) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	if err == io.EOF {
+		b.w.sent()
+	} else {
+		b.w.sending()
+	}
+
+	return n, err
+}
+
+// Close marks the request sent, as the transport may close the body without
+// reading it to the end.
+func (b *watchedRequest) Close() error {
+	err := b.ReadCloser.Close()
+	b.w.sent()
+
+	return err
+}
+
+// watchedResponse stops the watchdog once closed.
+type watchedResponse struct {
+	io.ReadCloser
+	w *watchdog
+}
+
+func (b *watchedResponse) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	switch {
+	case err == io.EOF:
+		b.w.arm(phaseReceiving, 0, nil)
+
+	case err != nil:
+		err = b.w.failure(err)
+
+	default:
+		b.w.receiving()
+	}
+
+	return n, err
+}
+
+func (b *watchedResponse) Close() error {
+	err := b.ReadCloser.Close()
+	b.w.stop()
+
+	return err
+}
@@ -0,0 +1,99 @@
+package instapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/instapi/client-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+
+	return len(p), nil
+}
+
+func TestTimeouts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload/accounts/acme/schemas/people/records":
+			// Stall the upload by not reading the request body
+			time.Sleep(200 * time.Millisecond)
+			return
+
+		case "/slow/accounts/acme/schemas/people":
+			time.Sleep(100 * time.Millisecond)
+
+		case "/stall/accounts/acme/schemas/people":
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte(`{"name": `))
+			w.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+
+			return
+		}
+
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"name": "people", "count": 1}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	// Overall timeouts
+	c := New(Endpoint(srv.URL+"/slow/"), Timeout(20*time.Millisecond), OperationTimeout(QueryOperation, time.Second))
+	_, err := c.GetSchema(ctx, "acme", "people")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = c.GetSchema(ctx, "acme", "people", RequestTimeout(time.Second))
+	require.ErrorIs(t, err, ErrStatus)
+
+	c = New(Endpoint(srv.URL+"/slow/"), OperationTimeout(ReadOperation, 20*time.Millisecond))
+	_, err = c.GetSchema(ctx, "acme", "people")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// First byte
+	c = New(Endpoint(srv.URL+"/slow/"), FirstByteTimeout(20*time.Millisecond))
+	_, err = c.GetSchema(ctx, "acme", "people")
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Contains(t, err.Error(), "no response")
+
+	// Stalled download
+	c = New(Endpoint(srv.URL+"/stall/"), IdleTimeout(20*time.Millisecond), FirstByteTimeout(time.Second))
+	_, err = c.GetSchema(ctx, "acme", "people")
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Contains(t, err.Error(), "response body stalled")
+
+	// Stalled upload
+	c = New(Endpoint(srv.URL+"/upload/"), IdleTimeout(20*time.Millisecond))
+	_, err = c.CreateRecords(ctx, "acme", "people", types.CSV, io.LimitReader(zeroReader{}, 1<<30))
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Contains(t, err.Error(), "request body stalled")
+
+	// Progressing requests complete
+	c = New(Endpoint(srv.URL+"/"), IdleTimeout(20*time.Millisecond))
+	n, err := c.CreateRecords(ctx, "acme", "people", types.CSV, strings.NewReader("a\n1\n"))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestOperation(t *testing.T) {
+	c := New()
+
+	require.Equal(t, QueryOperation, c.operation(http.MethodPost, c.endpoint+"query", strings.NewReader("SELECT 1")))
+	require.Equal(t, QueryOperation, c.operation(http.MethodPost, c.endpoint+"query/explain", nil))
+	require.Equal(t, UploadOperation, c.operation(http.MethodPost, c.endpoint+"accounts/a/schemas/s/records", strings.NewReader("")))
+	require.Equal(t, ReadOperation, c.operation(http.MethodGet, c.endpoint+"accounts/a", nil))
+	require.Equal(t, WriteOperation, c.operation(http.MethodPut, c.endpoint+"accounts/a", struct{}{}))
+}
@@ -0,0 +1,795 @@
+package transcode
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/instapi/client-go/schema"
+)
+
+// Avro errors.
+var (
+	ErrInvalidAvro      = errors.New("invalid Avro object container file")
+	ErrUnsupportedCodec = errors.New("unsupported Avro codec")
+)
+
+// Avro object container file constants.
+const (
+	avroMagic    = "Obj\x01"
+	avroSyncSize = 16
+	// maxAvroBlockSize guards against allocating corrupt block sizes.
+	maxAvroBlockSize = 1 << 30
+)
+
+// avroType represents a parsed Avro schema.
+type avroType struct {
+	Type        string
+	Name        string
+	LogicalType string
+	Scale       int
+	Size        int
+	Fields      []*avroField
+	Symbols     []string
+	Items       *avroType
+	Values      *avroType
+	Branches    []*avroType
+}
+
+type avroField struct {
+	Name string
+	Type *avroType
+}
+
+// avroReader reads the blocks of an Avro object container file.
+type avroReader struct {
+	r      *bufio.Reader
+	schema *avroType
+	codec  string
+	sync   []byte
+}
+
+func newAvroReader(r io.Reader) (*avroReader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(avroMagic))
+	_, err := io.ReadFull(br, magic)
+
+	if err != nil || string(magic) != avroMagic {
+		return nil, ErrInvalidAvro
+	}
+
+	meta := map[string][]byte{}
+
+	for {
+		n, err := readLong(br)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if n == 0 {
+			break
+		}
+
+		if n < 0 {
+			n = -n
+
+			// Skip the block size
+			if _, err = readLong(br); err != nil {
+				return nil, err
+			}
+		}
+
+		for i := int64(0); i < n; i++ {
+			k, err := readBytes(br)
+
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := readBytes(br)
+
+			if err != nil {
+				return nil, err
+			}
+
+			meta[string(k)] = v
+		}
+	}
+
+	a := &avroReader{r: br, codec: string(meta["avro.codec"]), sync: make([]byte, avroSyncSize)}
+
+	if _, err = io.ReadFull(br, a.sync); err != nil {
+		return nil, ErrInvalidAvro
+	}
+
+	var v interface{}
+
+	if err = json.Unmarshal(meta["avro.schema"], &v); err != nil {
+		return nil, fmt.Errorf("%w: schema: %v", ErrInvalidAvro, err)
+	}
+
+	a.schema, err = parseAvroType(v, "", map[string]*avroType{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// next returns the object count and decompressed data of the next block.
+func (a *avroReader) next() (int64, []byte, error) {
+	count, err := readLong(a.r)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	size, err := readLong(a.r)
+
+	if err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+
+	if size < 0 || size > maxAvroBlockSize {
+		return 0, nil, fmt.Errorf("%w: block size %d", ErrInvalidAvro, size)
+	}
+
+	b := make([]byte, size+avroSyncSize)
+
+	if _, err = io.ReadFull(a.r, b); err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+
+	if !bytes.Equal(b[size:], a.sync) {
+		return 0, nil, fmt.Errorf("%w: sync marker mismatch", ErrInvalidAvro)
+	}
+
+	b = b[:size]
+
+	switch a.codec {
+	case "", "null":
+
+	case "deflate":
+		b, err = io.ReadAll(flate.NewReader(bytes.NewReader(b)))
+
+	case "bzip2":
+		b, err = io.ReadAll(bzip2.NewReader(bytes.NewReader(b)))
+
+	default:
+		return 0, nil, fmt.Errorf("%w: %s", ErrUnsupportedCodec, a.codec)
+	}
+
+	return count, b, err
+}
+
+func avroToJSON(w io.Writer, r io.Reader) error {
+	a, err := newAvroReader(r)
+
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[') // nolint: errcheck
+
+	for i := 0; ; {
+		count, b, err := a.next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		d := &avroDecoder{b: b}
+
+		for ; count > 0; count-- {
+			// Write errors are sticky, stopping once the reader is closed
+			if i > 0 {
+				if err = bw.WriteByte(','); err != nil {
+					return err
+				}
+			}
+
+			if err = d.decode(bw, a.schema); err != nil {
+				return err
+			}
+
+			i++
+		}
+	}
+
+	bw.WriteByte(']') // nolint: errcheck
+
+	return bw.Flush()
+}
+
+// AvroSchema reads the schema of an Avro object container file and maps its
+// top level record to a schema. Nullable unions map to optional fields.
+func AvroSchema(r io.Reader, name string) (*schema.Schema, error) {
+	a, err := newAvroReader(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if a.schema.Type != "record" {
+		return nil, fmt.Errorf("%w: top level type %s is not a record", ErrInvalidAvro, a.schema.Type)
+	}
+
+	if name == "" {
+		name = a.schema.Name
+	}
+
+	s := &schema.Schema{Name: name, Fields: make([]*schema.Field, 0, len(a.schema.Fields))}
+
+	for _, v := range a.schema.Fields {
+		t, nullable := v.Type, false
+
+		if t.Type == "union" {
+			t, nullable = nonNull(t)
+		}
+
+		s.Fields = append(s.Fields, &schema.Field{Name: v.Name, Type: avroFieldType(t), Required: !nullable})
+	}
+
+	return s, nil
+}
+
+// nonNull returns the single non-null branch of a union, or the union itself.
+func nonNull(t *avroType) (*avroType, bool) {
+	var (
+		branch   *avroType
+		nullable bool
+	)
+
+	for _, v := range t.Branches {
+		if v.Type == "null" {
+			nullable = true
+		} else if branch == nil {
+			branch = v
+		} else {
+			return t, nullable
+		}
+	}
+
+	if branch == nil {
+		return t, nullable
+	}
+
+	return branch, nullable
+}
+
+func avroFieldType(t *avroType) schema.FieldType {
+	switch t.LogicalType {
+	case "date":
+		return schema.Date
+
+	case "time-millis", "time-micros":
+		return schema.Time
+
+	case "timestamp-millis", "timestamp-micros", "local-timestamp-millis", "local-timestamp-micros":
+		return schema.DateTime
+
+	case "decimal":
+		return schema.Decimal
+
+	case "uuid":
+		return schema.UUID
+	}
+
+	switch t.Type {
+	case "boolean":
+		return schema.Boolean
+
+	case "int", "long":
+		return schema.Integer
+
+	case "float", "double":
+		return schema.Number
+
+	case "string", "bytes", "fixed", "enum":
+		return schema.String
+
+	case "array":
+		return schema.Array
+
+	case "record", "map":
+		return schema.Object
+	}
+
+	return schema.JSON
+}
+
+func parseAvroType(v interface{}, namespace string, named map[string]*avroType) (*avroType, error) {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroType{Type: v}, nil
+		}
+
+		if t, exists := named[v]; exists {
+			return t, nil
+		}
+
+		if t, exists := named[qualify(v, namespace)]; exists {
+			return t, nil
+		}
+
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidAvro, v)
+
+	case []interface{}:
+		t := &avroType{Type: "union", Branches: make([]*avroType, len(v))}
+
+		for i, x := range v {
+			b, err := parseAvroType(x, namespace, named)
+
+			if err != nil {
+				return nil, err
+			}
+
+			t.Branches[i] = b
+		}
+
+		return t, nil
+
+	case map[string]interface{}:
+		return parseAvroObject(v, namespace, named)
+	}
+
+	return nil, fmt.Errorf("%w: invalid schema %v", ErrInvalidAvro, v)
+}
+
+func parseAvroObject(v map[string]interface{}, namespace string, named map[string]*avroType) (*avroType, error) {
+	typeName, _ := v["type"].(string)
+	logical, _ := v["logicalType"].(string)
+	scale, _ := v["scale"].(float64)
+	size, _ := v["size"].(float64)
+
+	switch typeName {
+	case "record", "error", "enum", "fixed":
+		name, _ := v["name"].(string)
+
+		if ns, ok := v["namespace"].(string); ok {
+			namespace = ns
+		}
+
+		t := &avroType{Type: typeName, Name: name, LogicalType: logical, Scale: int(scale), Size: int(size)}
+
+		// Register before parsing fields to support recursive types
+		named[name] = t
+		named[qualify(name, namespace)] = t
+
+		if typeName == "error" {
+			t.Type = "record"
+		}
+
+		fields, _ := v["fields"].([]interface{})
+
+		for _, x := range fields {
+			f, _ := x.(map[string]interface{})
+			fieldName, _ := f["name"].(string)
+			ft, err := parseAvroType(f["type"], namespace, named)
+
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldName, err)
+			}
+
+			t.Fields = append(t.Fields, &avroField{Name: fieldName, Type: ft})
+		}
+
+		symbols, _ := v["symbols"].([]interface{})
+
+		for _, x := range symbols {
+			s, _ := x.(string)
+			t.Symbols = append(t.Symbols, s)
+		}
+
+		return t, nil
+
+	case "array":
+		items, err := parseAvroType(v["items"], namespace, named)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &avroType{Type: typeName, Items: items}, nil
+
+	case "map":
+		values, err := parseAvroType(v["values"], namespace, named)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &avroType{Type: typeName, Values: values}, nil
+	}
+
+	t, err := parseAvroType(v["type"], namespace, named)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if logical != "" {
+		c := *t
+		c.LogicalType = logical
+		c.Scale = int(scale)
+		t = &c
+	}
+
+	return t, nil
+}
+
+func qualify(name, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+
+	return namespace + "." + name
+}
+
+// avroDecoder decodes Avro binary encoded values into JSON.
+type avroDecoder struct {
+	b   []byte
+	pos int
+}
+
+func (d *avroDecoder) decode(w *bufio.Writer, t *avroType) error {
+	switch t.Type {
+	case "null":
+		w.WriteString("null") // nolint: errcheck
+
+	case "boolean":
+		b, err := d.read(1)
+
+		if err != nil {
+			return err
+		}
+
+		w.WriteString(strconv.FormatBool(b[0] != 0)) // nolint: errcheck
+
+	case "int", "long":
+		v, err := d.long()
+
+		if err != nil {
+			return err
+		}
+
+		w.WriteString(formatLong(v, t.LogicalType)) // nolint: errcheck
+
+	case "float":
+		b, err := d.read(4)
+
+		if err != nil {
+			return err
+		}
+
+		writeFloat(w, float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 32)
+
+	case "double":
+		b, err := d.read(8)
+
+		if err != nil {
+			return err
+		}
+
+		writeFloat(w, math.Float64frombits(binary.LittleEndian.Uint64(b)), 64)
+
+	case "bytes", "string":
+		n, err := d.long()
+
+		if err != nil {
+			return err
+		}
+
+		b, err := d.read(int(n))
+
+		if err != nil {
+			return err
+		}
+
+		return writeBytes(w, b, t)
+
+	case "fixed":
+		b, err := d.read(t.Size)
+
+		if err != nil {
+			return err
+		}
+
+		return writeBytes(w, b, t)
+
+	case "enum":
+		i, err := d.long()
+
+		if err != nil {
+			return err
+		}
+
+		if i < 0 || int(i) >= len(t.Symbols) {
+			return fmt.Errorf("%w: enum index %d", ErrInvalidAvro, i)
+		}
+
+		return writeJSON(w, t.Symbols[i])
+
+	case "union":
+		i, err := d.long()
+
+		if err != nil {
+			return err
+		}
+
+		if i < 0 || int(i) >= len(t.Branches) {
+			return fmt.Errorf("%w: union index %d", ErrInvalidAvro, i)
+		}
+
+		return d.decode(w, t.Branches[i])
+
+	case "record":
+		w.WriteByte('{') // nolint: errcheck
+
+		for i, f := range t.Fields {
+			if i > 0 {
+				w.WriteByte(',') // nolint: errcheck
+			}
+
+			if err := writeJSON(w, f.Name); err != nil {
+				return err
+			}
+
+			w.WriteByte(':') // nolint: errcheck
+
+			if err := d.decode(w, f.Type); err != nil {
+				return err
+			}
+		}
+
+		w.WriteByte('}') // nolint: errcheck
+
+	case "array", "map":
+		return d.decodeBlocks(w, t)
+
+	default:
+		return fmt.Errorf("%w: type %s", ErrInvalidAvro, t.Type)
+	}
+
+	return nil
+}
+
+// decodeBlocks decodes the blocks of an array or map.
+func (d *avroDecoder) decodeBlocks(w *bufio.Writer, t *avroType) error {
+	start, end := byte('['), byte(']')
+
+	if t.Type == "map" {
+		start, end = '{', '}'
+	}
+
+	w.WriteByte(start) // nolint: errcheck
+
+	for i := 0; ; {
+		n, err := d.long()
+
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			break
+		}
+
+		if n < 0 {
+			n = -n
+
+			// Skip the block size
+			if _, err = d.long(); err != nil {
+				return err
+			}
+		}
+
+		for ; n > 0; n-- {
+			if i > 0 {
+				w.WriteByte(',') // nolint: errcheck
+			}
+
+			i++
+
+			if t.Type == "array" {
+				if err = d.decode(w, t.Items); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err = d.decode(w, &avroType{Type: "string"}); err != nil {
+				return err
+			}
+
+			w.WriteByte(':') // nolint: errcheck
+
+			if err = d.decode(w, t.Values); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.WriteByte(end) // nolint: errcheck
+
+	return nil
+}
+
+func (d *avroDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.b) {
+		return nil, fmt.Errorf("%w: truncated block", ErrInvalidAvro)
+	}
+
+	b := d.b[d.pos : d.pos+n]
+	d.pos += n
+
+	return b, nil
+}
+
+func (d *avroDecoder) long() (int64, error) {
+	u, n := binary.Uvarint(d.b[d.pos:])
+
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: invalid long", ErrInvalidAvro)
+	}
+
+	d.pos += n
+
+	return zigzag(u), nil
+}
+
+func formatLong(v int64, logical string) string {
+	var t time.Time
+
+	switch logical {
+	case "date":
+		return strconv.Quote(time.Unix(v*86400, 0).UTC().Format("2006-01-02"))
+
+	case "time-millis":
+		return strconv.Quote(time.UnixMilli(v).UTC().Format("15:04:05.999"))
+
+	case "time-micros":
+		return strconv.Quote(time.UnixMicro(v).UTC().Format("15:04:05.999999"))
+
+	case "timestamp-millis":
+		t = time.UnixMilli(v).UTC()
+
+	case "timestamp-micros":
+		t = time.UnixMicro(v).UTC()
+
+	case "local-timestamp-millis":
+		return strconv.Quote(time.UnixMilli(v).UTC().Format("2006-01-02T15:04:05.999"))
+
+	case "local-timestamp-micros":
+		return strconv.Quote(time.UnixMicro(v).UTC().Format("2006-01-02T15:04:05.999999"))
+
+	default:
+		return strconv.FormatInt(v, 10)
+	}
+
+	return strconv.Quote(t.Format(time.RFC3339Nano))
+}
+
+// writeFloat writes a float, or null for values not representable in JSON.
+func writeFloat(w *bufio.Writer, v float64, bits int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		w.WriteString("null") // nolint: errcheck
+		return
+	}
+
+	w.WriteString(strconv.FormatFloat(v, 'g', -1, bits)) // nolint: errcheck
+}
+
+// writeBytes writes strings as is, decimals as numbers and other binary
+// values base64 encoded.
+func writeBytes(w *bufio.Writer, b []byte, t *avroType) error {
+	switch {
+	case t.LogicalType == "decimal":
+		w.WriteString(formatDecimal(b, t.Scale)) // nolint: errcheck
+		return nil
+
+	case t.Type == "string":
+		return writeJSON(w, string(b))
+	}
+
+	return writeJSON(w, b)
+}
+
+// formatDecimal formats a big endian two's complement unscaled value.
+func formatDecimal(b []byte, scale int) string {
+	v := new(big.Int).SetBytes(b)
+
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+
+	s := v.String()
+
+	if scale <= 0 {
+		return s
+	}
+
+	sign := ""
+
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+func writeJSON(w *bufio.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+func readLong(r io.ByteReader) (int64, error) {
+	u, err := binary.ReadUvarint(r)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return zigzag(u), nil
+}
+
+func readBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := readLong(r)
+
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if n < 0 || n > maxAvroBlockSize {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidAvro, n)
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+
+	return b, unexpectedEOF(err)
+}
+
+func zigzag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,141 @@
+// Package transcode streams input formats the API does not accept natively
+// into CSV or JSON.
+package transcode
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/instapi/client-go/types"
+)
+
+// Target returns the content type the given content type is transcoded to,
+// or an empty string when the API accepts it natively.
+func Target(contentType string) string {
+	switch contentType {
+	case types.TSV:
+		return types.CSV
+
+	case types.NDJSON, types.Avro:
+		return types.JSON
+	}
+
+	return ""
+}
+
+// NewReader returns a reader streaming r transcoded into CSV or JSON along
+// with the resulting content type. Natively supported content types are
+// returned as is. TSV is transcoded into CSV, newline delimited JSON into a
+// JSON array and Avro object container files into a JSON array of objects.
+// Parquet is accepted by the API natively and returned as is. The reader must
+// be closed, which stops the transcoding when the reader is not read to the
+// end.
+func NewReader(contentType string, r io.Reader) (string, io.ReadCloser, error) {
+	var fn func(io.Writer, io.Reader) error
+
+	switch contentType {
+	case types.TSV:
+		fn = tsvToCSV
+
+	case types.NDJSON:
+		fn = ndjsonToJSON
+
+	case types.Avro:
+		fn = avroToJSON
+
+	default:
+		return contentType, nopCloser(r), nil
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(fn(pw, r)) // nolint: errcheck
+	}()
+
+	return Target(contentType), pr, nil
+}
+
+// nopCloser returns r with a no-op Close method, keeping it seekable so that
+// requests can be replayed.
+func nopCloser(r io.Reader) io.ReadCloser {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		return readSeekNopCloser{rs}
+	}
+
+	return io.NopCloser(r)
+}
+
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error {
+	return nil
+}
+
+func tsvToCSV(w io.Writer, r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	cw := csv.NewWriter(w)
+
+	for {
+		record, err := cr.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = cw.Write(record)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func ndjsonToJSON(w io.Writer, r io.Reader) error {
+	bw := bufio.NewWriter(w)
+	dec := json.NewDecoder(r)
+
+	bw.WriteByte('[') // nolint: errcheck
+
+	for i := 0; ; i++ {
+		var v json.RawMessage
+		err := dec.Decode(&v)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// Write errors are sticky, stopping once the reader is closed
+		if i > 0 {
+			if err = bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+
+		bw.Write(v) // nolint: errcheck
+	}
+
+	bw.WriteByte(']') // nolint: errcheck
+
+	return bw.Flush()
+}
@@ -0,0 +1,278 @@
+package transcode
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/instapi/client-go/schema"
+	"github.com/instapi/client-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func transcode(t *testing.T, contentType, input string) (string, string, error) {
+	target, r, err := NewReader(contentType, strings.NewReader(input))
+
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+
+	return target, string(b), err
+}
+
+func TestNewReaderNative(t *testing.T) {
+	r := strings.NewReader("a,b\n")
+	contentType, tr, err := NewReader(types.CSV, r)
+
+	require.NoError(t, err)
+	require.Equal(t, types.CSV, contentType)
+	require.Implements(t, (*io.Seeker)(nil), tr)
+
+	b, err := io.ReadAll(tr)
+
+	require.NoError(t, err)
+	require.Equal(t, "a,b\n", string(b))
+	require.NoError(t, tr.Close())
+
+	// Parquet is sent to the API as is
+	contentType, tr, err = NewReader(types.Parquet, strings.NewReader("PAR1"))
+
+	require.NoError(t, err)
+	require.Equal(t, types.Parquet, contentType)
+
+	b, err = io.ReadAll(tr)
+
+	require.NoError(t, err)
+	require.Equal(t, "PAR1", string(b))
+}
+
+func TestNewReaderClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	for _, contentType := range []string{types.TSV, types.NDJSON} {
+		input := strings.Repeat("{\"id\":1}\n", 100000)
+
+		if contentType == types.TSV {
+			input = strings.Repeat("id\tname\n", 100000)
+		}
+
+		_, r, err := NewReader(contentType, strings.NewReader(input))
+
+		require.NoError(t, err)
+
+		_, err = io.ReadFull(r, make([]byte, 16))
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+	}
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestTSV(t *testing.T) {
+	target, out, err := transcode(t, types.TSV, "id\tname\n1\tfoo, bar\n2\t\"quoted\"\"\"\n")
+
+	require.NoError(t, err)
+	require.Equal(t, types.CSV, target)
+	require.Equal(t, "id,name\n1,\"foo, bar\"\n2,\"quoted\"\"\"\n", out)
+}
+
+func TestNDJSON(t *testing.T) {
+	target, out, err := transcode(t, types.NDJSON, "{\"id\":1}\n\n{\"id\":2,\n\"a\":[1]}\n")
+
+	require.NoError(t, err)
+	require.Equal(t, types.JSON, target)
+	require.JSONEq(t, `[{"id":1},{"id":2,"a":[1]}]`, out)
+
+	_, out, err = transcode(t, types.NDJSON, "")
+
+	require.NoError(t, err)
+	require.Equal(t, "[]", out)
+
+	_, _, err = transcode(t, types.NDJSON, "{\"id\":1}\n{")
+	require.Error(t, err)
+}
+
+const testAvroSchema = `{
+	"type": "record",
+	"name": "Person",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "name", "type": ["null", "string"]},
+		{"name": "score", "type": "double"},
+		{"name": "active", "type": "boolean"},
+		{"name": "born", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "seen", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "balance", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "attrs", "type": {"type": "map", "values": "int"}},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+		{"name": "parent", "type": ["null", "Person"]}
+	]
+}`
+
+type avroWriter struct {
+	bytes.Buffer
+}
+
+func (w *avroWriter) long(v int64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	w.Write(b[:binary.PutUvarint(b, uint64((v<<1)^(v>>63)))])
+}
+
+func (w *avroWriter) bytes(b []byte) {
+	w.long(int64(len(b)))
+	w.Write(b)
+}
+
+func (w *avroWriter) string(s string) {
+	w.bytes([]byte(s))
+}
+
+func (w *avroWriter) double(v float64) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+	w.Write(b)
+}
+
+func (w *avroWriter) person(id int64, name string, parent bool) {
+	w.long(id)
+
+	if name == "" {
+		w.long(0)
+	} else {
+		w.long(1)
+		w.string(name)
+	}
+
+	w.double(1.5)
+	w.WriteByte(1)
+	w.long(19000)
+	w.long(1700000000123)
+	w.bytes([]byte{0xff, 0x85}) // -123
+	w.long(2)
+	w.string("x")
+	w.string("y")
+	w.long(0)
+	w.long(-1) // Negative block count followed by the block size
+	w.long(2)
+	w.string("k")
+	w.long(7)
+	w.long(0)
+	w.long(1)
+
+	if parent {
+		w.long(1)
+		w.person(0, "", false)
+	} else {
+		w.long(0)
+	}
+}
+
+func avroFile(t *testing.T, codec string, blocks ...[]byte) []byte {
+	var w avroWriter
+
+	sync := []byte("0123456789abcdef")
+
+	w.WriteString("Obj\x01")
+	w.long(2)
+	w.string("avro.schema")
+	w.string(testAvroSchema)
+	w.string("avro.codec")
+	w.string(codec)
+	w.long(0)
+	w.Write(sync)
+
+	for _, b := range blocks {
+		if codec == "deflate" {
+			var buf bytes.Buffer
+
+			fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+			require.NoError(t, err)
+			_, err = fw.Write(b)
+			require.NoError(t, err)
+			require.NoError(t, fw.Close())
+
+			b = buf.Bytes()
+		}
+
+		w.long(1)
+		w.long(int64(len(b)))
+		w.Write(b)
+		w.Write(sync)
+	}
+
+	return w.Bytes()
+}
+
+func TestAvro(t *testing.T) {
+	var first, second avroWriter
+
+	first.person(1, "Alice", true)
+	second.person(2, "", false)
+
+	for _, codec := range []string{"null", "deflate"} {
+		target, out, err := transcode(t, types.Avro, string(avroFile(t, codec, first.Bytes(), second.Bytes())))
+
+		require.NoError(t, err, codec)
+		require.Equal(t, types.JSON, target)
+		require.JSONEq(t, `[
+			{"id":1,"name":"Alice","score":1.5,"active":true,"born":"2022-01-08","seen":"2023-11-14T22:13:20.123Z",
+			 "balance":-1.23,"tags":["x","y"],"attrs":{"k":7},"kind":"B",
+			 "parent":{"id":0,"name":null,"score":1.5,"active":true,"born":"2022-01-08","seen":"2023-11-14T22:13:20.123Z",
+			           "balance":-1.23,"tags":["x","y"],"attrs":{"k":7},"kind":"B","parent":null}},
+			{"id":2,"name":null,"score":1.5,"active":true,"born":"2022-01-08","seen":"2023-11-14T22:13:20.123Z",
+			 "balance":-1.23,"tags":["x","y"],"attrs":{"k":7},"kind":"B","parent":null}
+		]`, out, codec)
+	}
+}
+
+func TestAvroErrors(t *testing.T) {
+	_, _, err := transcode(t, types.Avro, "not avro")
+	require.ErrorIs(t, err, ErrInvalidAvro)
+
+	_, _, err = transcode(t, types.Avro, string(avroFile(t, "snappy", []byte{0})))
+	require.ErrorIs(t, err, ErrUnsupportedCodec)
+
+	b := avroFile(t, "null", []byte{2})
+	b[len(b)-1] = 'x'
+
+	_, _, err = transcode(t, types.Avro, string(b))
+	require.ErrorIs(t, err, ErrInvalidAvro)
+}
+
+func TestAvroSchema(t *testing.T) {
+	s, err := AvroSchema(bytes.NewReader(avroFile(t, "null")), "")
+
+	require.NoError(t, err)
+	require.Equal(t, "Person", s.Name)
+
+	fields := map[string]*schema.Field{}
+
+	for _, v := range s.Fields {
+		fields[v.Name] = v
+	}
+
+	require.Len(t, s.Fields, 11)
+	require.Equal(t, schema.Integer, fields["id"].Type)
+	require.True(t, fields["id"].Required)
+	require.Equal(t, schema.String, fields["name"].Type)
+	require.False(t, fields["name"].Required)
+	require.Equal(t, schema.Date, fields["born"].Type)
+	require.Equal(t, schema.DateTime, fields["seen"].Type)
+	require.Equal(t, schema.Decimal, fields["balance"].Type)
+	require.Equal(t, schema.Array, fields["tags"].Type)
+	require.Equal(t, schema.Object, fields["attrs"].Type)
+	require.Equal(t, schema.String, fields["kind"].Type)
+	require.Equal(t, schema.Object, fields["parent"].Type)
+}
@@ -0,0 +1,243 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// Detection errors.
+var (
+	ErrUndetectable = errors.New("unable to detect content type")
+)
+
+// sniffLen is the number of leading bytes inspected by Detect.
+const sniffLen = 8192
+
+// maxSniffLines is the number of text lines inspected for a delimiter.
+const maxSniffLines = 10
+
+var (
+	sqliteMagic  = []byte("SQLite format 3\x00")
+	ole2Magic    = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+	zipMagic     = []byte("PK\x03\x04")
+	gzipMagic    = []byte{0x1f, 0x8b}
+	bzip2Magic   = []byte("BZh")
+	zstdMagic    = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	tarMagic     = []byte("ustar")
+	odsMimetype  = []byte("mimetype" + ODS)
+	parquetMagic = []byte("PAR1")
+	avroMagic    = []byte("Obj\x01")
+)
+
+// Detect sniffs the content type of r from its leading bytes. It recognises
+// SQLite databases, XLSX and ODS spreadsheets, OLE2 (XLS) workbooks, Parquet
+// and Avro files, archives and compressed streams, JSON and newline delimited
+// JSON, and CSV and TSV.
+// The returned reader yields the whole input, including the sniffed bytes,
+// and is returned along with ErrUndetectable so callers can fall back. A
+// seekable r is rewound and returned as is.
+func Detect(r io.Reader) (string, io.Reader, error) {
+	b, r, err := peek(r)
+
+	if err != nil {
+		return "", r, err
+	}
+
+	eof := len(b) < sniffLen
+
+	switch {
+	case bytes.HasPrefix(b, sqliteMagic):
+		return SQLite, r, nil
+
+	case bytes.HasPrefix(b, ole2Magic):
+		return XLS, r, nil
+
+	case bytes.HasPrefix(b, parquetMagic):
+		return Parquet, r, nil
+
+	case bytes.HasPrefix(b, avroMagic):
+		return Avro, r, nil
+
+	case bytes.HasPrefix(b, zipMagic):
+		return zipType(b), r, nil
+
+	case bytes.HasPrefix(b, gzipMagic):
+		return Gzip, r, nil
+
+	case bytes.HasPrefix(b, bzip2Magic):
+		return Bzip2, r, nil
+
+	case bytes.HasPrefix(b, zstdMagic):
+		return Zstd, r, nil
+
+	case len(b) > 262 && bytes.Equal(b[257:262], tarMagic):
+		return Tar, r, nil
+	}
+
+	if !isText(b, eof) {
+		return "", r, ErrUndetectable
+	}
+
+	switch firstByte(b) {
+	case 0:
+		return "", r, ErrUndetectable
+
+	case '[':
+		return JSON, r, nil
+
+	case '{':
+		if isNDJSON(b) {
+			return NDJSON, r, nil
+		}
+
+		return JSON, r, nil
+	}
+
+	if isTSV(b, eof) {
+		return TSV, r, nil
+	}
+
+	return CSV, r, nil
+}
+
+func peek(r io.Reader) ([]byte, io.Reader, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		offset, err := rs.Seek(0, io.SeekCurrent)
+
+		if err == nil {
+			b := make([]byte, sniffLen)
+			n, err := io.ReadFull(rs, b)
+
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return nil, r, err
+			}
+
+			_, err = rs.Seek(offset, io.SeekStart)
+
+			return b[:n], r, err
+		}
+	}
+
+	br := bufio.NewReaderSize(r, sniffLen)
+	b, err := br.Peek(sniffLen)
+
+	if err != nil && err != io.EOF {
+		return nil, br, err
+	}
+
+	return b, br, nil
+}
+
+// zipType distinguishes spreadsheets from other ZIP archives by their entry
+// names. ODS files start with an uncompressed mimetype entry.
+func zipType(b []byte) string {
+	switch {
+	case bytes.Contains(b, odsMimetype):
+		return ODS
+
+	case bytes.Contains(b, []byte("xl/")):
+		return XLSX
+
+	case bytes.Contains(b, []byte("[Content_Types].xml")) &&
+		!bytes.Contains(b, []byte("word/")) && !bytes.Contains(b, []byte("ppt/")):
+		return XLSX
+	}
+
+	return Zip
+}
+
+// isText reports whether b is valid UTF-8 without NUL bytes, ignoring a rune
+// truncated by the sniff length.
+func isText(b []byte, eof bool) bool {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return false
+	}
+
+	if !eof {
+		for i := 0; i < utf8.UTFMax && len(b) > 0 && !utf8.Valid(b); i++ {
+			b = b[:len(b)-1]
+		}
+	}
+
+	return utf8.Valid(b)
+}
+
+func firstByte(b []byte) byte {
+	b = bytes.TrimLeft(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")), " \t\r\n")
+
+	if len(b) == 0 {
+		return 0
+	}
+
+	return b[0]
+}
+
+// isNDJSON reports whether the first line holds a complete JSON value
+// followed by another object, as opposed to a pretty printed object.
+func isNDJSON(b []byte) bool {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	i := bytes.IndexByte(b, '\n')
+
+	if i < 0 || !json.Valid(b[:i]) {
+		return false
+	}
+
+	return firstByte(b[i:]) == '{'
+}
+
+// isTSV reports whether the complete leading lines hold the same non-zero
+// number of tabs, at least as many as commas, outside of quoted fields.
+func isTSV(b []byte, eof bool) bool {
+	lines := bytes.Split(b, []byte("\n"))
+
+	if !eof && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+
+	tabs := -1
+
+	for i, line := range lines {
+		if i == maxSniffLines {
+			break
+		}
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		t, c := countDelimiters(line)
+
+		if t == 0 || t < c || (tabs >= 0 && t != tabs) {
+			return false
+		}
+
+		tabs = t
+	}
+
+	return tabs > 0
+}
+
+func countDelimiters(line []byte) (tabs, commas int) {
+	quoted := false
+
+	for _, v := range line {
+		switch {
+		case v == '"':
+			quoted = !quoted
+
+		case quoted:
+
+		case v == '\t':
+			tabs++
+
+		case v == ',':
+			commas++
+		}
+	}
+
+	return tabs, commas
+}
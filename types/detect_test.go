@@ -0,0 +1,86 @@
+package types
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"sqlite", "SQLite format 3\x00\x10\x00", SQLite},
+		{"xls", "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1\x00\x00", XLS},
+		{"xlsx", "PK\x03\x04\x14\x00[Content_Types].xml\x00xl/workbook.xml", XLSX},
+		{"ods", "PK\x03\x04\x0a\x00mimetypeapplication/vnd.oasis.opendocument.spreadsheet", ODS},
+		{"zip", "PK\x03\x04\x14\x00word/document.xml", Zip},
+		{"gzip", "\x1f\x8b\x08\x00", Gzip},
+		{"parquet", "PAR1\x15\x04", Parquet},
+		{"avro", "Obj\x01\x04", Avro},
+		{"json array", "\xef\xbb\xbf  [{\"a\": 1}]", JSON},
+		{"json object", "{\n  \"a\": 1\n}\n", JSON},
+		{"ndjson", "{\"a\": 1}\n{\"a\": 2}\n", NDJSON},
+		{"csv", "a,b\n1,2\n", CSV},
+		{"csv single column", "name\nfoo\n", CSV},
+		{"csv quoted tabs", "a,b\n\"x\ty\",2\n", CSV},
+		{"tsv", "a\tb\tc\n1\t\"x,y\"\t3\n", TSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, r, err := Detect(strings.NewReader(tt.input))
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, contentType)
+
+			b, err := io.ReadAll(r)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.input, string(b))
+		})
+	}
+}
+
+func TestDetectTar(t *testing.T) {
+	b := make([]byte, 512)
+	copy(b, "file.csv")
+	copy(b[257:], "ustar\x0000")
+
+	contentType, _, err := Detect(bytes.NewReader(b))
+
+	require.NoError(t, err)
+	require.Equal(t, Tar, contentType)
+}
+
+func TestDetectUndetectable(t *testing.T) {
+	for _, v := range []string{"", " \n", "\x00\x01\x02\x03"} {
+		_, r, err := Detect(strings.NewReader(v))
+
+		require.ErrorIs(t, err, ErrUndetectable)
+
+		b, err := io.ReadAll(r)
+
+		require.NoError(t, err)
+		require.Equal(t, v, string(b))
+	}
+}
+
+func TestDetectLargeInput(t *testing.T) {
+	input := "a,b\n" + strings.Repeat("1,é\n", sniffLen)
+
+	contentType, r, err := Detect(io.MultiReader(strings.NewReader(input)))
+
+	require.NoError(t, err)
+	require.Equal(t, CSV, contentType)
+
+	b, err := io.ReadAll(r)
+
+	require.NoError(t, err)
+	require.Equal(t, input, string(b))
+}
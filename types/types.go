@@ -13,32 +13,49 @@ var (
 
 // Constants for types package.
 const (
-	mimeXLS  = "application/vnd.ms-excel"
-	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-	CSV      = "text/csv"
-	JSON     = "application/json"
-	ODS      = "application/vnd.oasis.opendocument.spreadsheet"
-	SQL      = "application/sql"
-	SQLite   = "application/x-sqlite3"
-	XLA      = mimeXLS
-	XLS      = mimeXLS
-	XLAM     = mimeXLSX
-	XLSB     = "application/vnd.ms-excel.sheet.binary.macroEnabled.12"
-	XLSM     = mimeXLSX
-	XLSX     = mimeXLSX
+	mimeXLS    = "application/vnd.ms-excel"
+	mimeXLSX   = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	Avro       = "application/avro"
+	Bzip2      = "application/x-bzip2"
+	CSV        = "text/csv"
+	Gzip       = "application/gzip"
+	JSON       = "application/json"
+	JSONPatch  = "application/json-patch+json"
+	MergePatch = "application/merge-patch+json"
+	NDJSON     = "application/x-ndjson"
+	ODS        = "application/vnd.oasis.opendocument.spreadsheet"
+	Parquet    = "application/vnd.apache.parquet"
+	SQL        = "application/sql"
+	SQLite     = "application/x-sqlite3"
+	Tar        = "application/x-tar"
+	TSV        = "text/tab-separated-values"
+	XLA        = mimeXLS
+	XLS        = mimeXLS
+	XLAM       = mimeXLSX
+	XLSB       = "application/vnd.ms-excel.sheet.binary.macroEnabled.12"
+	XLSM       = mimeXLSX
+	XLSX       = mimeXLSX
+	Zip        = "application/zip"
+	Zstd       = "application/zstd"
 )
 
 var contentTypes = map[string]string{
-	".csv":  CSV,
-	".db":   SQLite,
-	".json": JSON,
-	".ods":  ODS,
-	".xls":  XLS,
-	".xla":  XLA,
-	".xlsx": XLSX,
-	".xlsm": XLSM,
-	".xlam": XLAM,
-	".xlsb": XLSB,
+	".avro":    Avro,
+	".csv":     CSV,
+	".db":      SQLite,
+	".json":    JSON,
+	".jsonl":   NDJSON,
+	".ndjson":  NDJSON,
+	".ods":     ODS,
+	".parquet": Parquet,
+	".tab":     TSV,
+	".tsv":     TSV,
+	".xls":     XLS,
+	".xla":     XLA,
+	".xlsx":    XLSX,
+	".xlsm":    XLSM,
+	".xlam":    XLAM,
+	".xlsb":    XLSB,
 }
 
 // TypeFromExt returns the content type for the given file extension.